@@ -0,0 +1,35 @@
+//go:build go1.17
+
+package sbvector
+
+import "unsafe"
+
+// hostLittleEndian is true if the host stores integers in little endian,
+// that is same byte order as the binary format.
+var hostLittleEndian = func() bool {
+	var x uint16 = 1
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+// aliasUint64s returns uint64 slice that shares memory with `data`.
+// It returns nil if `data` can not be aliased because of the alignment or the byte order.
+func aliasUint64s(data []byte) []uint64 {
+	if !canAlias(data, unsafe.Alignof(uint64(0))) {
+		return nil
+	}
+	return unsafe.Slice((*uint64)(unsafe.Pointer(&data[0])), uint64(len(data))/sizeOfInt64)
+}
+
+// aliasRankIndexes returns rankIndex slice that shares memory with `data`.
+// It returns nil if `data` can not be aliased because of the alignment or the byte order.
+func aliasRankIndexes(data []byte) []rankIndex {
+	var tmpRankIndex rankIndex
+	if !canAlias(data, unsafe.Alignof(tmpRankIndex)) {
+		return nil
+	}
+	return unsafe.Slice((*rankIndex)(unsafe.Pointer(&data[0])), uintptr(len(data))/unsafe.Sizeof(tmpRankIndex))
+}
+
+func canAlias(data []byte, align uintptr) bool {
+	return hostLittleEndian && len(data) > 0 && uintptr(unsafe.Pointer(&data[0]))%align == 0
+}
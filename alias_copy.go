@@ -0,0 +1,13 @@
+//go:build !go1.17
+
+package sbvector
+
+// aliasUint64s returns nil, because unsafe.Slice is not available. The caller copies `data`.
+func aliasUint64s(data []byte) []uint64 {
+	return nil
+}
+
+// aliasRankIndexes returns nil, because unsafe.Slice is not available. The caller copies `data`.
+func aliasRankIndexes(data []byte) []rankIndex {
+	return nil
+}
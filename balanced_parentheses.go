@@ -0,0 +1,398 @@
+package sbvector
+
+import (
+	"errors"
+)
+
+// bpBlockSize is number of bits in a leaf of the range min-max tree.
+const bpBlockSize = lBlockSize
+
+// ErrorUnbalanced indicates that the parentheses are not balanced.
+var ErrorUnbalanced = errors.New("Unbalanced parentheses")
+
+// Excess tables of bytes. For a byte whose prefix excesses of the first j bits are P_j,
+// bpByteExcess is P_8, bpByteMin is the minimum of P_1..P_8, and bpByteMinExcl is the minimum of P_0..P_7.
+var (
+	bpByteExcess  [256]int8
+	bpByteMin     [256]int8
+	bpByteMinExcl [256]int8
+)
+
+func init() {
+	for b := 0; b < 256; b++ {
+		var ex, m, mExcl int8 = 0, 8, 0
+		for j := uint(0); j < 8; j++ {
+			if ex < mExcl {
+				mExcl = ex
+			}
+			if (b>>j)&1 == 1 {
+				ex++
+			} else {
+				ex--
+			}
+			if ex < m {
+				m = ex
+			}
+		}
+		bpByteExcess[b] = ex
+		bpByteMin[b] = m
+		bpByteMinExcl[b] = mExcl
+	}
+}
+
+// BalancedParentheses is ordinal tree represented by balanced parentheses.
+// `1` is an open parenthesis and `0` is a close parenthesis, and each node is identified by the position of its open parenthesis.
+// The range min-max tree over excess values answers the navigational queries in O(log n) time.
+type BalancedParentheses struct {
+	vec        *BitVectorData
+	numOfLeaf  uint64
+	excess     []int64
+	minExcess  []int64
+	numOfNodes uint64
+}
+
+// NewBalancedParentheses returns new balanced parentheses that wraps `vec`.
+// `vec` must be built, and its parentheses must be balanced.
+func NewBalancedParentheses(vec *BitVectorData) (*BalancedParentheses, error) {
+	bp := &BalancedParentheses{vec: vec, numOfLeaf: 1}
+	var size = vec.Size()
+	var leafNum = (size + bpBlockSize - 1) / bpBlockSize
+	for bp.numOfLeaf < leafNum {
+		bp.numOfLeaf <<= 1
+	}
+	bp.excess = make([]int64, 2*bp.numOfLeaf)
+	bp.minExcess = make([]int64, 2*bp.numOfLeaf)
+	for leaf := uint64(0); leaf < leafNum; leaf++ {
+		m, ex := bp.minScan(leaf*bpBlockSize, bp.leafEnd(leaf), 0, 0)
+		bp.excess[bp.numOfLeaf+leaf] = ex
+		bp.minExcess[bp.numOfLeaf+leaf] = m
+	}
+	for v := bp.numOfLeaf - 1; v >= 1; v-- {
+		var l, r = 2 * v, 2*v + 1
+		bp.excess[v] = bp.excess[l] + bp.excess[r]
+		bp.minExcess[v] = bp.minExcess[l]
+		if bp.excess[l]+bp.minExcess[r] < bp.minExcess[v] {
+			bp.minExcess[v] = bp.excess[l] + bp.minExcess[r]
+		}
+	}
+	if bp.excess[1] != 0 || bp.minExcess[1] < 0 {
+		return nil, ErrorUnbalanced
+	}
+	bp.numOfNodes = vec.NumOfBits(true)
+	return bp, nil
+}
+
+// BitVector returns the bit vector that holds the parentheses.
+func (bp *BalancedParentheses) BitVector() *BitVectorData {
+	return bp.vec
+}
+
+// NumOfNodes returns number of the nodes in the tree.
+func (bp *BalancedParentheses) NumOfNodes() uint64 {
+	return bp.numOfNodes
+}
+
+// FindClose returns the position of the close parenthesis that matches the open parenthesis at position `i`.
+// If `i` is a close parenthesis, returns `i`.
+func (bp *BalancedParentheses) FindClose(i uint64) (uint64, error) {
+	if i >= bp.vec.Size() {
+		return NotFound, ErrorOutOfRange
+	}
+	if !bp.isOpen(i) {
+		return i, nil
+	}
+	var p = bp.fwdSearch(i+1, bp.prefixExcess(i+1)-1)
+	return p - 1, nil
+}
+
+// FindOpen returns the position of the open parenthesis that matches the close parenthesis at position `i`.
+// If `i` is an open parenthesis, returns `i`.
+func (bp *BalancedParentheses) FindOpen(i uint64) (uint64, error) {
+	if i >= bp.vec.Size() {
+		return NotFound, ErrorOutOfRange
+	}
+	if bp.isOpen(i) {
+		return i, nil
+	}
+	return bp.bwdSearch(i+1, bp.prefixExcess(i+1)), nil
+}
+
+// Enclose returns the position of the open parenthesis of the parent of the node at position `i`.
+// If the node is a root, returns NotFound.
+func (bp *BalancedParentheses) Enclose(i uint64) (uint64, error) {
+	i, err := bp.FindOpen(i)
+	if err != nil {
+		return NotFound, err
+	}
+	return bp.bwdSearch(i, bp.prefixExcess(i)-1), nil
+}
+
+// Depth returns number of the ancestors of the node at position `i`. Depth of a root is 0.
+func (bp *BalancedParentheses) Depth(i uint64) (uint64, error) {
+	i, err := bp.FindOpen(i)
+	if err != nil {
+		return NotFound, err
+	}
+	return uint64(bp.prefixExcess(i)), nil
+}
+
+// LevelAncestor returns the position of the open parenthesis of the ancestor `d` levels above the node at position `i`.
+// LevelAncestor(i, 0) is the node itself. If `d` is greater than the depth of the node, returns NotFound.
+func (bp *BalancedParentheses) LevelAncestor(i uint64, d uint64) (uint64, error) {
+	i, err := bp.FindOpen(i)
+	if err != nil {
+		return NotFound, err
+	}
+	var ex = bp.prefixExcess(i)
+	if d == 0 {
+		return i, nil
+	}
+	if d > uint64(ex) {
+		return NotFound, nil
+	}
+	return bp.bwdSearch(i, ex-int64(d)), nil
+}
+
+// SubtreeSize returns number of the nodes in the subtree rooted at the node at position `i`.
+func (bp *BalancedParentheses) SubtreeSize(i uint64) (uint64, error) {
+	i, err := bp.FindOpen(i)
+	if err != nil {
+		return NotFound, err
+	}
+	c, _ := bp.FindClose(i)
+	return (c - i + 1) / 2, nil
+}
+
+// LCA returns the position of the open parenthesis of the lowest common ancestor of the nodes at position `i` and `j`.
+// If the nodes are in different trees of the forest, returns NotFound.
+func (bp *BalancedParentheses) LCA(i uint64, j uint64) (uint64, error) {
+	i, err := bp.FindOpen(i)
+	if err != nil {
+		return NotFound, err
+	}
+	j, err = bp.FindOpen(j)
+	if err != nil {
+		return NotFound, err
+	}
+	if i > j {
+		i, j = j, i
+	}
+	if c, _ := bp.FindClose(i); j < c {
+		return i, nil
+	}
+	// Excess is minimum at the close parentheses of the children of the ancestor between `i` and `j`.
+	var m = bp.rangeMinExcess(i+1, j+1)
+	return bp.bwdSearch(i+1, m-1), nil
+}
+
+func (bp *BalancedParentheses) isOpen(i uint64) bool {
+	return (bp.vec.blocks[i/sBlockSize]>>(i%sBlockSize))&1 == 1
+}
+
+// delta returns the change of excess by the parenthesis at position `i`.
+func (bp *BalancedParentheses) delta(i uint64) int64 {
+	if bp.isOpen(i) {
+		return 1
+	}
+	return -1
+}
+
+// prefixExcess returns number of open parentheses minus number of close parentheses in the first `p` parentheses.
+func (bp *BalancedParentheses) prefixExcess(p uint64) int64 {
+	rank, _ := bp.vec.Rank1(p)
+	return int64(2*rank) - int64(p)
+}
+
+// leafEnd returns the end of the range of the leaf.
+func (bp *BalancedParentheses) leafEnd(leaf uint64) uint64 {
+	var end = (leaf + 1) * bpBlockSize
+	if end > bp.vec.Size() {
+		end = bp.vec.Size()
+	}
+	return end
+}
+
+// fwdSearch returns the smallest `p` > `p0` such that prefixExcess(p) <= target.
+// target must be less than prefixExcess(p0).
+func (bp *BalancedParentheses) fwdSearch(p0 uint64, target int64) uint64 {
+	if p0 >= bp.vec.Size() {
+		return NotFound
+	}
+	var leaf = p0 / bpBlockSize
+	p, ex := bp.fwdScan(p0, bp.leafEnd(leaf), bp.prefixExcess(p0), target)
+	if p != NotFound {
+		return p
+	}
+	var v = bp.numOfLeaf + leaf
+	for ; v > 1; v >>= 1 {
+		if v&1 == 0 {
+			if ex+bp.minExcess[v+1] <= target {
+				v++
+				break
+			}
+			ex += bp.excess[v+1]
+		}
+	}
+	if v == 1 {
+		return NotFound
+	}
+	for v < bp.numOfLeaf {
+		if ex+bp.minExcess[2*v] <= target {
+			v = 2 * v
+		} else {
+			ex += bp.excess[2*v]
+			v = 2*v + 1
+		}
+	}
+	leaf = v - bp.numOfLeaf
+	p, _ = bp.fwdScan(leaf*bpBlockSize, bp.leafEnd(leaf), ex, target)
+	return p
+}
+
+// bwdSearch returns the largest `p` < `p0` such that prefixExcess(p) <= target.
+// target must be less than prefixExcess(p0).
+func (bp *BalancedParentheses) bwdSearch(p0 uint64, target int64) uint64 {
+	if p0 == 0 {
+		return NotFound
+	}
+	var leaf = (p0 - 1) / bpBlockSize
+	p, ex := bp.bwdScan(p0, leaf*bpBlockSize, bp.prefixExcess(p0), target)
+	if p != NotFound {
+		return p
+	}
+	var v = bp.numOfLeaf + leaf
+	for ; v > 1; v >>= 1 {
+		if v&1 == 1 {
+			if ex-bp.excess[v-1]+bp.minExcess[v-1] <= target {
+				v--
+				break
+			}
+			ex -= bp.excess[v-1]
+		}
+	}
+	if v == 1 {
+		return NotFound
+	}
+	for v < bp.numOfLeaf {
+		if ex-bp.excess[2*v+1]+bp.minExcess[2*v+1] <= target {
+			v = 2*v + 1
+		} else {
+			ex -= bp.excess[2*v+1]
+			v = 2 * v
+		}
+	}
+	leaf = v - bp.numOfLeaf
+	p, _ = bp.bwdScan(bp.leafEnd(leaf), leaf*bpBlockSize, ex, target)
+	return p
+}
+
+// rangeMinExcess returns the minimum of prefixExcess(p) for `a` <= p <= `b`.
+func (bp *BalancedParentheses) rangeMinExcess(a uint64, b uint64) int64 {
+	var ex = bp.prefixExcess(a)
+	var m = ex
+	if a%bpBlockSize != 0 {
+		var end = (a/bpBlockSize + 1) * bpBlockSize
+		if end > b {
+			end = b
+		}
+		m, _ = bp.minScan(a, end, ex, m)
+		a = end
+	}
+	// Leaves between `a` and `b` are covered by nodes of the tree.
+	var l = a/bpBlockSize + bp.numOfLeaf
+	var r = b/bpBlockSize + bp.numOfLeaf
+	for h := uint64(0); l < r; h++ {
+		if l&1 == 1 {
+			m = bp.nodeMinExcess(l, h, m)
+			l++
+		}
+		if r&1 == 1 {
+			r--
+			m = bp.nodeMinExcess(r, h, m)
+		}
+		l >>= 1
+		r >>= 1
+	}
+	if p := (b / bpBlockSize) * bpBlockSize; p > a {
+		a = p
+	}
+	m, _ = bp.minScan(a, b, bp.prefixExcess(a), m)
+	return m
+}
+
+// byteAt returns 8 parentheses from position `p`. `p` must be a multiple of 8.
+func (bp *BalancedParentheses) byteAt(p uint64) uint8 {
+	return uint8(bp.vec.blocks[p/sBlockSize] >> (p % sBlockSize))
+}
+
+// fwdScan returns the smallest `q` in range (p, end] such that excess at `q` <= target, where excess at `p` is `ex`.
+// If there is no such `q`, returns NotFound and excess at `end`.
+func (bp *BalancedParentheses) fwdScan(p uint64, end uint64, ex int64, target int64) (uint64, int64) {
+	for p < end {
+		if p%8 == 0 && p+8 <= end {
+			var x = bp.byteAt(p)
+			if ex+int64(bpByteMin[x]) > target {
+				ex += int64(bpByteExcess[x])
+				p += 8
+				continue
+			}
+		}
+		ex += bp.delta(p)
+		p++
+		if ex <= target {
+			return p, ex
+		}
+	}
+	return NotFound, ex
+}
+
+// bwdScan returns the largest `q` in range [begin, p) such that excess at `q` <= target, where excess at `p` is `ex`.
+// If there is no such `q`, returns NotFound and excess at `begin`.
+func (bp *BalancedParentheses) bwdScan(p uint64, begin uint64, ex int64, target int64) (uint64, int64) {
+	for p > begin {
+		if p%8 == 0 && p-8 >= begin {
+			var x = bp.byteAt(p - 8)
+			if ex-int64(bpByteExcess[x])+int64(bpByteMinExcl[x]) > target {
+				ex -= int64(bpByteExcess[x])
+				p -= 8
+				continue
+			}
+		}
+		p--
+		ex -= bp.delta(p)
+		if ex <= target {
+			return p, ex
+		}
+	}
+	return NotFound, ex
+}
+
+// minScan returns the smaller of `m` and the minimum excess in range (p, end], and excess at `end`, where excess at `p` is `ex`.
+func (bp *BalancedParentheses) minScan(p uint64, end uint64, ex int64, m int64) (int64, int64) {
+	for p < end {
+		if p%8 == 0 && p+8 <= end {
+			var x = bp.byteAt(p)
+			if ex+int64(bpByteMin[x]) < m {
+				m = ex + int64(bpByteMin[x])
+			}
+			ex += int64(bpByteExcess[x])
+			p += 8
+			continue
+		}
+		ex += bp.delta(p)
+		p++
+		if ex < m {
+			m = ex
+		}
+	}
+	return m, ex
+}
+
+// nodeMinExcess returns the smaller of `m` and the minimum excess in the node `v` of height `h`.
+func (bp *BalancedParentheses) nodeMinExcess(v uint64, h uint64, m int64) int64 {
+	var start = ((v << h) - bp.numOfLeaf) * bpBlockSize
+	if x := bp.prefixExcess(start) + bp.minExcess[v]; x < m {
+		return x
+	}
+	return m
+}
@@ -0,0 +1,157 @@
+package sbvector
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBalancedParentheses(t *testing.T) {
+	// (()(()()))()
+	var parens = "(()(()()))()"
+	builder := NewVectorBuilder()
+	for _, c := range parens {
+		builder.PushBack(c == '(')
+	}
+	vec, _ := builder.Build(true, true)
+	bp, err := NewBalancedParentheses(vec.(*BitVectorData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := bp.NumOfNodes(); n != 6 {
+		t.Error("Expected", 6, "got", n)
+	}
+	if c, err := bp.FindClose(0); err != nil || c != 9 {
+		t.Error("Expected", 9, "got", c)
+	}
+	if o, err := bp.FindOpen(8); err != nil || o != 3 {
+		t.Error("Expected", 3, "got", o)
+	}
+	if p, err := bp.Enclose(4); err != nil || p != 3 {
+		t.Error("Expected", 3, "got", p)
+	}
+	if p, err := bp.Enclose(10); err != nil || p != NotFound {
+		t.Error("Expected", NotFound, "got", p)
+	}
+	if d, err := bp.Depth(6); err != nil || d != 2 {
+		t.Error("Expected", 2, "got", d)
+	}
+	if s, err := bp.SubtreeSize(0); err != nil || s != 5 {
+		t.Error("Expected", 5, "got", s)
+	}
+	if a, err := bp.LCA(1, 6); err != nil || a != 0 {
+		t.Error("Expected", 0, "got", a)
+	}
+	if a, err := bp.LCA(1, 10); err != nil || a != NotFound {
+		t.Error("Expected", NotFound, "got", a)
+	}
+	if _, err := bp.FindClose(12); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+
+	for _, s := range []string{"(()", "())(", ")("} {
+		builder := NewVectorBuilder()
+		for _, c := range s {
+			builder.PushBack(c == '(')
+		}
+		vec, _ := builder.Build(false, false)
+		if _, err := NewBalancedParentheses(vec.(*BitVectorData)); err != ErrorUnbalanced {
+			t.Error("Expected", ErrorUnbalanced, "got", err)
+		}
+	}
+}
+
+func TestBalancedParenthesesRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{1, 300, 5000} {
+		// Random forest of `n` nodes.
+		var bits []bool
+		var depth int
+		for opened := 0; opened < n || depth > 0; {
+			if opened < n && (depth == 0 || r.Intn(2) == 0) {
+				bits = append(bits, true)
+				opened++
+				depth++
+			} else {
+				bits = append(bits, false)
+				depth--
+			}
+		}
+		var match = make([]uint64, len(bits))
+		var parent = make([]uint64, len(bits))
+		var depths = make([]uint64, len(bits))
+		var stack []uint64
+		for i, b := range bits {
+			if b {
+				parent[i] = NotFound
+				if len(stack) > 0 {
+					parent[i] = stack[len(stack)-1]
+				}
+				depths[i] = uint64(len(stack))
+				stack = append(stack, uint64(i))
+			} else {
+				var o = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				match[i] = o
+				match[o] = uint64(i)
+			}
+		}
+
+		builder := NewVectorBuilder()
+		for _, b := range bits {
+			builder.PushBack(b)
+		}
+		vec, _ := builder.Build(false, false)
+		bp, err := NewBalancedParentheses(vec.(*BitVectorData))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var opens []uint64
+		for i, b := range bits {
+			var open = uint64(i)
+			if b {
+				opens = append(opens, open)
+				if c, err := bp.FindClose(open); err != nil || c != match[i] {
+					t.Fatal("FindClose", i, "Expected", match[i], "got", c)
+				}
+			} else {
+				open = match[i]
+				if o, err := bp.FindOpen(uint64(i)); err != nil || o != open {
+					t.Fatal("FindOpen", i, "Expected", open, "got", o)
+				}
+			}
+			if p, err := bp.Enclose(uint64(i)); err != nil || p != parent[open] {
+				t.Fatal("Enclose", i, "Expected", parent[open], "got", p)
+			}
+			if d, err := bp.Depth(uint64(i)); err != nil || d != depths[open] {
+				t.Fatal("Depth", i, "Expected", depths[open], "got", d)
+			}
+			if s, err := bp.SubtreeSize(uint64(i)); err != nil || s != (match[open]-open+1)/2 {
+				t.Fatal("SubtreeSize", i, "got", s)
+			}
+			var ancestor = open
+			for d := uint64(0); d <= depths[open]+1; d++ {
+				if a, err := bp.LevelAncestor(uint64(i), d); err != nil || a != ancestor {
+					t.Fatal("LevelAncestor", i, d, "Expected", ancestor, "got", a)
+				}
+				if ancestor != NotFound {
+					ancestor = parent[ancestor]
+				}
+			}
+		}
+		for k := 0; k < 2000; k++ {
+			var x = opens[r.Intn(len(opens))]
+			var y = opens[r.Intn(len(opens))]
+			var ancestors = make(map[uint64]bool)
+			for a := x; a != NotFound; a = parent[a] {
+				ancestors[a] = true
+			}
+			var lca = y
+			for lca != NotFound && !ancestors[lca] {
+				lca = parent[lca]
+			}
+			if a, err := bp.LCA(x, y); err != nil || a != lca {
+				t.Fatal("LCA", x, y, "Expected", lca, "got", a)
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package sbvector
+
+// Rank1Batch stores Rank1 of each position in `positions` into `out`.
+// `positions` must be sorted in ascending order, and `out` must be as long as `positions` at least.
+func (vec *BitVectorData) Rank1Batch(positions []uint64, out []uint64) error {
+	if len(out) < len(positions) {
+		return ErrorOutOfRange
+	}
+	var rankID = NotFound
+	var rank *rankIndex
+	for k, i := range positions {
+		if i > vec.size {
+			return ErrorOutOfRange
+		}
+		if k > 0 && i < positions[k-1] {
+			return ErrorNotSorted
+		}
+		// The rank index is looked up only when the position moves to next large block.
+		if i/lBlockSize != rankID {
+			rankID = i / lBlockSize
+			rank = &vec.ranks[rankID]
+		}
+		out[k] = rank.abs() + vec.rankInLargeBlock(rank, i)
+	}
+	return nil
+}
+
+// Select1Batch stores Select1 of each value in `ks` into `out`.
+// `ks` must be sorted in ascending order, and `out` must be as long as `ks` at least.
+func (vec *BitVectorData) Select1Batch(ks []uint64, out []uint64) error {
+	if len(out) < len(ks) {
+		return ErrorOutOfRange
+	}
+	var numOfRanks = uint64(len(vec.ranks))
+	var rankID uint64
+	for k, x := range ks {
+		if x >= vec.numOf1s {
+			return ErrorOutOfRange
+		}
+		if k > 0 && x < ks[k-1] {
+			return ErrorNotSorted
+		}
+		// select1Table bounds the large blocks to walk, and the walk starts from the large block of the previous value.
+		var end = numOfRanks
+		if len(vec.select1Table) > 0 {
+			var selectID = x / lBlockSize
+			var begin = vec.select1Table[selectID] / lBlockSize
+			if begin > rankID {
+				rankID = begin
+			}
+			if x%lBlockSize == 0 {
+				out[k] = vec.select1Table[selectID]
+				continue
+			}
+			end = (vec.select1Table[selectID+1] + lBlockSize - 1) / lBlockSize
+		}
+		// Gallop to the last large block that begins at or before the x-th 1.
+		var step = uint64(1)
+		for rankID+step < end && vec.ranks[rankID+step].abs() <= x {
+			rankID += step
+			step *= 2
+		}
+		for step > 1 {
+			step /= 2
+			if rankID+step < end && vec.ranks[rankID+step].abs() <= x {
+				rankID += step
+			}
+		}
+		out[k] = vec.select1InLargeBlock(rankID, x)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package sbvector
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, density := range []int{1, 30, 99} {
+		builder := NewVectorBuilder()
+		for i := 0; i < 20000; i++ {
+			builder.PushBack(r.Intn(100) < density)
+		}
+		vec, _ := builder.Build(true, false)
+		bv := vec.(*BitVectorData)
+
+		var positions []uint64
+		for i := uint64(0); i <= vec.Size(); i += uint64(r.Intn(700)) {
+			positions = append(positions, i, i)
+		}
+		positions = append(positions, vec.Size())
+		var out = make([]uint64, len(positions))
+		if err := bv.Rank1Batch(positions, out); err != nil {
+			t.Fatal(err)
+		}
+		for k, i := range positions {
+			if rank, _ := vec.Rank1(i); out[k] != rank {
+				t.Fatal("Rank1Batch", i, "Expected", rank, "got", out[k])
+			}
+		}
+
+		var ks []uint64
+		for x := uint64(0); x < vec.NumOfBits(true); x += uint64(r.Intn(2000)) {
+			ks = append(ks, x)
+		}
+		ks = append(ks, vec.NumOfBits(true)-1)
+		var aligned []uint64
+		for x := uint64(0); x < vec.NumOfBits(true); x += lBlockSize {
+			aligned = append(aligned, x)
+			if x+1 < vec.NumOfBits(true) {
+				aligned = append(aligned, x+1)
+			}
+		}
+		noTable, _ := NewVectorBuilderWithInit(vec).Build(false, false)
+		for _, v := range []*BitVectorData{bv, noTable.(*BitVectorData)} {
+			for _, ks := range [][]uint64{ks, aligned} {
+				out = make([]uint64, len(ks))
+				if err := v.Select1Batch(ks, out); err != nil {
+					t.Fatal(err)
+				}
+				for k, x := range ks {
+					if pos, _ := vec.Select1(x); out[k] != pos {
+						t.Fatal("Select1Batch", x, "Expected", pos, "got", out[k])
+					}
+				}
+			}
+		}
+		out = make([]uint64, len(ks))
+
+		if err := bv.Rank1Batch([]uint64{5, 3}, out); err != ErrorNotSorted {
+			t.Error("Expected", ErrorNotSorted, "got", err)
+		}
+		if err := bv.Rank1Batch([]uint64{vec.Size() + 1}, out); err != ErrorOutOfRange {
+			t.Error("Expected", ErrorOutOfRange, "got", err)
+		}
+		if err := bv.Select1Batch([]uint64{1, 0}, out); err != ErrorNotSorted {
+			t.Error("Expected", ErrorNotSorted, "got", err)
+		}
+		if err := bv.Select1Batch([]uint64{vec.NumOfBits(true)}, out); err != ErrorOutOfRange {
+			t.Error("Expected", ErrorOutOfRange, "got", err)
+		}
+		if err := bv.Select1Batch(ks, out[:len(ks)-1]); err != ErrorOutOfRange {
+			t.Error("Expected", ErrorOutOfRange, "got", err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package sbvector
+
+// And returns new succinct bit vector that holds bitwise AND of `a` and `b`.
+// Size of the result is the larger of the sizes, and bits beyond the size of the shorter vector are treated as 0.
+func And(a SuccinctBitVector, b SuccinctBitVector) (SuccinctBitVector, error) {
+	return combine(a, b, func(x uint64, y uint64) uint64 { return x & y })
+}
+
+// Or returns new succinct bit vector that holds bitwise OR of `a` and `b`.
+// Size of the result is the larger of the sizes, and bits beyond the size of the shorter vector are treated as 0.
+func Or(a SuccinctBitVector, b SuccinctBitVector) (SuccinctBitVector, error) {
+	return combine(a, b, func(x uint64, y uint64) uint64 { return x | y })
+}
+
+// Xor returns new succinct bit vector that holds bitwise XOR of `a` and `b`.
+// Size of the result is the larger of the sizes, and bits beyond the size of the shorter vector are treated as 0.
+func Xor(a SuccinctBitVector, b SuccinctBitVector) (SuccinctBitVector, error) {
+	return combine(a, b, func(x uint64, y uint64) uint64 { return x ^ y })
+}
+
+// AndNot returns new succinct bit vector that holds bits of `a` that are not set in `b`.
+// Size of the result is the larger of the sizes, and bits beyond the size of the shorter vector are treated as 0.
+func AndNot(a SuccinctBitVector, b SuccinctBitVector) (SuccinctBitVector, error) {
+	return combine(a, b, func(x uint64, y uint64) uint64 { return x &^ y })
+}
+
+// Not returns new succinct bit vector that holds bitwise NOT of `a`.
+func Not(a SuccinctBitVector) (SuccinctBitVector, error) {
+	words, err := vectorWords(a)
+	if err != nil {
+		return nil, err
+	}
+	var size = a.Size()
+	blocks := make([]uint64, (size+sBlockSize-1)/sBlockSize)
+	for i := range blocks {
+		blocks[i] = ^wordAt(words, size, uint64(i))
+	}
+	if r := size % sBlockSize; r != 0 {
+		blocks[len(blocks)-1] = mask(blocks[len(blocks)-1], r)
+	}
+	return newVectorFromBlocks(blocks, size), nil
+}
+
+func combine(a SuccinctBitVector, b SuccinctBitVector, op func(x uint64, y uint64) uint64) (SuccinctBitVector, error) {
+	wordsA, err := vectorWords(a)
+	if err != nil {
+		return nil, err
+	}
+	wordsB, err := vectorWords(b)
+	if err != nil {
+		return nil, err
+	}
+	var size = a.Size()
+	if b.Size() > size {
+		size = b.Size()
+	}
+	blocks := make([]uint64, (size+sBlockSize-1)/sBlockSize)
+	for i := range blocks {
+		blocks[i] = op(wordAt(wordsA, a.Size(), uint64(i)), wordAt(wordsB, b.Size(), uint64(i)))
+	}
+	return newVectorFromBlocks(blocks, size), nil
+}
+
+// vectorWords returns bits of `vec` in 64 bits words.
+// Blocks of BitVectorData are returned as is, other vectors are decoded by GetBits.
+func vectorWords(vec SuccinctBitVector) ([]uint64, error) {
+	if bv, ok := vec.(*BitVectorData); ok {
+		return bv.blocks, nil
+	}
+	var size = vec.Size()
+	words := make([]uint64, (size+sBlockSize-1)/sBlockSize)
+	for i := range words {
+		var pos = uint64(i) * sBlockSize
+		var length = sBlockSize
+		if pos+length > size {
+			length = size - pos
+		}
+		x, err := vec.GetBits(pos, length)
+		if err != nil {
+			return nil, err
+		}
+		words[i] = x
+	}
+	return words, nil
+}
+
+// wordAt returns the i-th word of the vector of `size` bits, bits beyond `size` are 0.
+func wordAt(words []uint64, size uint64, i uint64) uint64 {
+	var pos = i * sBlockSize
+	if pos >= size || i >= uint64(len(words)) {
+		return 0
+	}
+	if pos+sBlockSize > size {
+		return mask(words[i], size-pos)
+	}
+	return words[i]
+}
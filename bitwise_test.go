@@ -0,0 +1,70 @@
+package sbvector
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBitwise(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	newRandomVector := func(size int, rrr bool) (SuccinctBitVector, []bool) {
+		builder := NewVectorBuilder()
+		bits := make([]bool, size)
+		for i := range bits {
+			bits[i] = r.Intn(3) == 0
+			builder.PushBack(bits[i])
+		}
+		if rrr {
+			vec, _ := builder.BuildRRR()
+			return vec, bits
+		}
+		vec, _ := builder.Build(false, false)
+		return vec, bits
+	}
+	bitAt := func(bits []bool, i int) bool {
+		return i < len(bits) && bits[i]
+	}
+
+	ops := []struct {
+		name string
+		fn   func(a SuccinctBitVector, b SuccinctBitVector) (SuccinctBitVector, error)
+		op   func(x bool, y bool) bool
+	}{
+		{"And", And, func(x bool, y bool) bool { return x && y }},
+		{"Or", Or, func(x bool, y bool) bool { return x || y }},
+		{"Xor", Xor, func(x bool, y bool) bool { return x != y }},
+		{"AndNot", AndNot, func(x bool, y bool) bool { return x && !y }},
+	}
+
+	for _, sizes := range [][2]int{{1000, 1000}, {1000, 70}, {64, 3000}, {0, 130}} {
+		a, bitsA := newRandomVector(sizes[0], false)
+		b, bitsB := newRandomVector(sizes[1], sizes[1] > 1000)
+		for _, op := range ops {
+			vec, err := op.fn(a, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var size = len(bitsA)
+			if len(bitsB) > size {
+				size = len(bitsB)
+			}
+			builder := NewVectorBuilder()
+			for i := 0; i < size; i++ {
+				builder.PushBack(op.op(bitAt(bitsA, i), bitAt(bitsB, i)))
+			}
+			expected, _ := builder.Build(true, true)
+			compareVectors(t, expected, vec)
+		}
+
+		vec, err := Not(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		builder := NewVectorBuilder()
+		for _, x := range bitsB {
+			builder.PushBack(!x)
+		}
+		expected, _ := builder.Build(true, true)
+		compareVectors(t, expected, vec)
+	}
+}
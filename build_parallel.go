@@ -0,0 +1,133 @@
+package sbvector
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/hideo55/go-popcount"
+)
+
+// minParallelBlocks is the minimum number of blocks that a goroutine of buildParallel handles.
+const minParallelBlocks = 1 << 14
+
+// buildParallel creates the same indexes as build by `numOfWorkers` goroutines.
+// Blocks are split into chunks aligned to large blocks. Each chunk is counted concurrently,
+// and then absolute ranks and select tables are fixed up by number of 1s before the chunk.
+func (vec *BitVectorData) buildParallel(enableFasterSelect1 bool, enableFasterSelect0 bool, numOfWorkers int) {
+	if numOfWorkers <= 0 {
+		numOfWorkers = runtime.NumCPU()
+	}
+	var blockNum = uint64(len(vec.blocks))
+	var chunkSize = (blockNum + uint64(numOfWorkers) - 1) / uint64(numOfWorkers)
+	if chunkSize < minParallelBlocks {
+		chunkSize = minParallelBlocks
+	}
+	chunkSize = (chunkSize + blockRate - 1) / blockRate * blockRate
+	if numOfWorkers == 1 || chunkSize >= blockNum {
+		vec.build(enableFasterSelect1, enableFasterSelect0)
+		return
+	}
+	var chunkNum = (blockNum + chunkSize - 1) / chunkSize
+
+	var rankTableSize = (blockNum*sBlockSize)/lBlockSize + 1
+	if ((blockNum * sBlockSize) % lBlockSize) != 0 {
+		rankTableSize++
+	}
+	vec.ranks = make([]rankIndex, rankTableSize)
+
+	// Count 1s of each chunk, ranks hold absolute values from the head of the chunk.
+	var counts = make([]uint64, chunkNum)
+	var wg sync.WaitGroup
+	for c := uint64(0); c < chunkNum; c++ {
+		wg.Add(1)
+		go func(c uint64) {
+			defer wg.Done()
+			var count uint64
+			for i := c * chunkSize; i < blockNum && i < (c+1)*chunkSize; i++ {
+				var rank = &vec.ranks[i/blockRate]
+				if i%blockRate == 0 {
+					rank.setAbs(count)
+				} else {
+					rank.setRelOf(i%blockRate, count-rank.abs())
+				}
+				count += popcount.Count(vec.blocks[i])
+			}
+			counts[c] = count
+		}(c)
+	}
+	wg.Wait()
+
+	var offsets = make([]uint64, chunkNum+1)
+	for c := uint64(0); c < chunkNum; c++ {
+		offsets[c+1] = offsets[c] + counts[c]
+	}
+	vec.numOf1s = offsets[chunkNum]
+
+	// Fix up absolute ranks, and collect positions of every lBlockSize-th 1 and 0 in each chunk.
+	var select1Tables = make([][]uint64, chunkNum)
+	var select0Tables = make([][]uint64, chunkNum)
+	for c := uint64(0); c < chunkNum; c++ {
+		wg.Add(1)
+		go func(c uint64) {
+			defer wg.Done()
+			var begin = c * chunkSize
+			var end = begin + chunkSize
+			if end > blockNum {
+				end = blockNum
+			}
+			for rankID := begin / blockRate; rankID < (end+blockRate-1)/blockRate; rankID++ {
+				vec.ranks[rankID].setAbs(vec.ranks[rankID].abs() + offsets[c])
+			}
+			if enableFasterSelect1 {
+				select1Tables[c] = vec.selectPositions(begin, end, offsets[c], true)
+			}
+			if enableFasterSelect0 {
+				select0Tables[c] = vec.selectPositions(begin, end, begin*sBlockSize-offsets[c], false)
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	if (blockNum % blockRate) != 0 {
+		var rank = &vec.ranks[(blockNum-1)/blockRate]
+		for k := (blockNum-1)%blockRate + 1; k < blockRate; k++ {
+			rank.setRelOf(k, vec.numOf1s-rank.abs())
+		}
+	}
+	vec.ranks[len(vec.ranks)-1].setAbs(vec.numOf1s)
+
+	vec.select1Table = nil
+	vec.select0Table = nil
+	if enableFasterSelect1 {
+		for _, table := range select1Tables {
+			vec.select1Table = append(vec.select1Table, table...)
+		}
+		vec.select1Table = append(vec.select1Table, vec.size)
+	}
+	if enableFasterSelect0 {
+		for _, table := range select0Tables {
+			vec.select0Table = append(vec.select0Table, table...)
+		}
+		vec.select0Table = append(vec.select0Table, vec.size)
+	}
+}
+
+// selectPositions returns positions of every lBlockSize-th `b` in blocks [begin, end).
+// `count` is number of `b` before the block `begin`. Like build, 0s after the end of the vector in the last block are counted.
+func (vec *BitVectorData) selectPositions(begin uint64, end uint64, count uint64, b bool) []uint64 {
+	var positions []uint64
+	var next = (count + lBlockSize - 1) / lBlockSize * lBlockSize
+	for i := begin; i < end; i++ {
+		var x = vec.blocks[i]
+		if !b {
+			x = ^x
+		}
+		var c = popcount.Count(x)
+		if next < count+c {
+			positions = append(positions, select64(x, next-count, i*sBlockSize))
+			next += lBlockSize
+		}
+		count += c
+	}
+	return positions
+}
@@ -0,0 +1,32 @@
+package sbvector
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestBuildParallel(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var largeSize = int(minParallelBlocks*sBlockSize)*3 + 4321
+	for _, size := range []int{0, 1000, largeSize} {
+		for _, density := range []int{0, 3, 50, 100} {
+			builder1 := NewVectorBuilder()
+			builder2 := NewVectorBuilder()
+			for i := 0; i < size; i++ {
+				b := r.Intn(100) < density
+				builder1.PushBack(b)
+				builder2.PushBack(b)
+			}
+			var enableFasterSelect1 = density != 50
+			vec1, _ := builder1.Build(enableFasterSelect1, true)
+			vec2, err := builder2.BuildParallel(enableFasterSelect1, true, density%7)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(vec1, vec2) {
+				t.Fatal("Expected same vector", size, density)
+			}
+		}
+	}
+}
@@ -0,0 +1,518 @@
+package sbvector
+
+import (
+	"github.com/hideo55/go-popcount"
+)
+
+const (
+	// dynamicLeafBlocks is the maximum number of blocks in a leaf of DynamicVectorData.
+	dynamicLeafBlocks = 32
+	dynamicLeafBits   = dynamicLeafBlocks * sBlockSize
+)
+
+// DynamicVectorData is bit vector that supports insertion and deletion of bits.
+// Bits are held in leaves of a balanced binary tree, so that every operation takes O(log n) time.
+type DynamicVectorData struct {
+	root *dynamicNode
+}
+
+// dynamicNode is a node of DynamicVectorData.
+// Leaves hold bits, and internal nodes hold number of bits and 1s of their subtree.
+type dynamicNode struct {
+	left    *dynamicNode
+	right   *dynamicNode
+	height  int
+	size    uint64
+	numOf1s uint64
+	blocks  []uint64
+}
+
+// NewDynamicVector returns new empty dynamic bit vector.
+func NewDynamicVector() *DynamicVectorData {
+	return &DynamicVectorData{newDynamicLeaf(nil, 0)}
+}
+
+// NewDynamicVectorFromVector returns new dynamic bit vector that holds same bits as `vec`.
+func NewDynamicVectorFromVector(vec SuccinctBitVector) (*DynamicVectorData, error) {
+	words, err := vectorWords(vec)
+	if err != nil {
+		return nil, err
+	}
+	var size = vec.Size()
+	// Leaves are filled by half so that insertions do not split them immediately.
+	var leafBlocks = uint64(dynamicLeafBlocks / 2)
+	var leaves []*dynamicNode
+	for i := uint64(0); i*sBlockSize < size; i += leafBlocks {
+		var end = i + leafBlocks
+		if end*sBlockSize > size {
+			end = (size + sBlockSize - 1) / sBlockSize
+		}
+		blocks := make([]uint64, end-i, dynamicLeafBlocks)
+		for j := range blocks {
+			blocks[j] = wordAt(words, size, i+uint64(j))
+		}
+		var length = (end - i) * sBlockSize
+		if end*sBlockSize > size {
+			length = size - i*sBlockSize
+		}
+		leaves = append(leaves, newDynamicLeaf(blocks, length))
+	}
+	if len(leaves) == 0 {
+		return NewDynamicVector(), nil
+	}
+	return &DynamicVectorData{buildDynamicTree(leaves)}, nil
+}
+
+// Build returns succinct bit vector that holds same bits as the dynamic bit vector.
+// If `enableFasterSelect1` is true, creates index for select1 make faster.
+// If `enableFasterSelect0` is true, creates index for select0 make faster.
+func (vec *DynamicVectorData) Build(enableFasterSelect1 bool, enableFasterSelect0 bool) (SuccinctBitVector, error) {
+	bv := new(BitVectorData)
+	bv.blocks = make([]uint64, 0, (vec.Size()+sBlockSize-1)/sBlockSize)
+	vec.root.each(func(leaf *dynamicNode) {
+		for i, x := range leaf.blocks {
+			var length = leaf.size - uint64(i)*sBlockSize
+			if length > sBlockSize {
+				length = sBlockSize
+			}
+			bv.pushBackBits(x, length)
+		}
+	})
+	bv.build(enableFasterSelect1, enableFasterSelect0)
+	return bv, nil
+}
+
+// Size returns number of bits in the dynamic bit vector.
+func (vec *DynamicVectorData) Size() uint64 {
+	return vec.root.size
+}
+
+// NumOfBits returns number of bits that matches with argument in the dynamic bit vector.
+func (vec *DynamicVectorData) NumOfBits(b bool) uint64 {
+	if b {
+		return vec.root.numOf1s
+	}
+	return vec.root.size - vec.root.numOf1s
+}
+
+// Get returns value from the dynamic bit vector by index.
+func (vec *DynamicVectorData) Get(i uint64) (bool, error) {
+	if i >= vec.Size() {
+		return false, ErrorOutOfRange
+	}
+	var node = vec.root
+	for node.left != nil {
+		if i < node.left.size {
+			node = node.left
+		} else {
+			i -= node.left.size
+			node = node.right
+		}
+	}
+	return (node.blocks[i/sBlockSize] & (1 << (i % sBlockSize))) != 0, nil
+}
+
+// Set sets the value of the i-th bit.
+func (vec *DynamicVectorData) Set(i uint64, b bool) error {
+	if i >= vec.Size() {
+		return ErrorOutOfRange
+	}
+	old, _ := vec.Get(i)
+	if old == b {
+		return nil
+	}
+	var node = vec.root
+	for {
+		if b {
+			node.numOf1s++
+		} else {
+			node.numOf1s--
+		}
+		if node.left == nil {
+			break
+		}
+		if i < node.left.size {
+			node = node.left
+		} else {
+			i -= node.left.size
+			node = node.right
+		}
+	}
+	node.blocks[i/sBlockSize] ^= 1 << (i % sBlockSize)
+	return nil
+}
+
+// Insert inserts the bit `b` at position `i`. Bits at position `i` and after are shifted by one.
+func (vec *DynamicVectorData) Insert(i uint64, b bool) error {
+	if i > vec.Size() {
+		return ErrorOutOfRange
+	}
+	vec.root = vec.root.insert(i, b)
+	return nil
+}
+
+// PushBack adds the bit `b` at the end of the dynamic bit vector.
+func (vec *DynamicVectorData) PushBack(b bool) {
+	vec.root = vec.root.insert(vec.Size(), b)
+}
+
+// Delete removes the i-th bit. Bits after position `i` are shifted by one.
+func (vec *DynamicVectorData) Delete(i uint64) error {
+	if i >= vec.Size() {
+		return ErrorOutOfRange
+	}
+	vec.root = vec.root.delete(i)
+	if vec.root == nil {
+		vec.root = newDynamicLeaf(nil, 0)
+	}
+	return nil
+}
+
+// Rank1 returns number of the bits equal to `1` up to position `i`
+func (vec *DynamicVectorData) Rank1(i uint64) (uint64, error) {
+	if i > vec.Size() {
+		return NotFound, ErrorOutOfRange
+	}
+	var rank uint64
+	var node = vec.root
+	for node.left != nil {
+		if i < node.left.size {
+			node = node.left
+		} else {
+			i -= node.left.size
+			rank += node.left.numOf1s
+			node = node.right
+		}
+	}
+	for j := uint64(0); j < i/sBlockSize; j++ {
+		rank += popcount.Count(node.blocks[j])
+	}
+	if r := i % sBlockSize; r != 0 {
+		rank += popcount.Count(mask(node.blocks[i/sBlockSize], r))
+	}
+	return rank, nil
+}
+
+// Rank0 returns number of the bits equal to `0` up to position `i`
+func (vec *DynamicVectorData) Rank0(i uint64) (uint64, error) {
+	rank, err := vec.Rank1(i)
+	if err != nil {
+		return NotFound, err
+	}
+	return i - rank, nil
+}
+
+// Rank returns number of the bits equal to `b` up to position `i`
+func (vec *DynamicVectorData) Rank(i uint64, b bool) (uint64, error) {
+	if b {
+		return vec.Rank1(i)
+	}
+	return vec.Rank0(i)
+}
+
+// Select1 returns the position of the x-th occurrence of 1
+func (vec *DynamicVectorData) Select1(x uint64) (uint64, error) {
+	return vec.Select(x, true)
+}
+
+// Select0 returns the position of the x-th occurrence of 0
+func (vec *DynamicVectorData) Select0(x uint64) (uint64, error) {
+	return vec.Select(x, false)
+}
+
+// Select returns the position of the x-th occurrence of `b`
+func (vec *DynamicVectorData) Select(x uint64, b bool) (uint64, error) {
+	if x >= vec.NumOfBits(b) {
+		return NotFound, ErrorOutOfRange
+	}
+	var pos uint64
+	var node = vec.root
+	for node.left != nil {
+		var count = node.left.count(b)
+		if x < count {
+			node = node.left
+		} else {
+			x -= count
+			pos += node.left.size
+			node = node.right
+		}
+	}
+	for j, block := range node.blocks {
+		if !b {
+			block = ^block
+		}
+		var count = popcount.Count(block)
+		if x < count {
+			return select64(block, x, pos+uint64(j)*sBlockSize), nil
+		}
+		x -= count
+	}
+	return NotFound, ErrorOutOfRange
+}
+
+// newDynamicLeaf returns new leaf that holds `length` bits in `blocks`.
+func newDynamicLeaf(blocks []uint64, length uint64) *dynamicNode {
+	leaf := &dynamicNode{blocks: blocks, size: length}
+	for _, x := range blocks {
+		leaf.numOf1s += popcount.Count(x)
+	}
+	return leaf
+}
+
+// buildDynamicTree returns balanced tree whose leaves are `leaves`.
+func buildDynamicTree(leaves []*dynamicNode) *dynamicNode {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	var half = len(leaves) / 2
+	node := &dynamicNode{left: buildDynamicTree(leaves[:half]), right: buildDynamicTree(leaves[half:])}
+	node.update()
+	return node
+}
+
+// count returns number of the bits equal to `b` in the subtree.
+func (node *dynamicNode) count(b bool) uint64 {
+	if b {
+		return node.numOf1s
+	}
+	return node.size - node.numOf1s
+}
+
+// each calls `fn` for every leaf in the subtree from left to right.
+func (node *dynamicNode) each(fn func(leaf *dynamicNode)) {
+	if node.left == nil {
+		fn(node)
+		return
+	}
+	node.left.each(fn)
+	node.right.each(fn)
+}
+
+// update recomputes the fields of the internal node from its children.
+func (node *dynamicNode) update() {
+	node.size = node.left.size + node.right.size
+	node.numOf1s = node.left.numOf1s + node.right.numOf1s
+	node.height = node.left.height + 1
+	if node.right.height >= node.height {
+		node.height = node.right.height + 1
+	}
+}
+
+// insert inserts the bit `b` at position `i` of the subtree, and returns new root of the subtree.
+func (node *dynamicNode) insert(i uint64, b bool) *dynamicNode {
+	if node.left == nil {
+		node.insertBit(i, b)
+		if node.size < dynamicLeafBits {
+			return node
+		}
+		return node.split()
+	}
+	if i <= node.left.size {
+		node.left = node.left.insert(i, b)
+	} else {
+		node.right = node.right.insert(i-node.left.size, b)
+	}
+	return node.balance()
+}
+
+// delete removes the i-th bit of the subtree, and returns new root of the subtree.
+// nil is returned if the subtree becomes empty.
+func (node *dynamicNode) delete(i uint64) *dynamicNode {
+	if node.left == nil {
+		node.deleteBit(i)
+		if node.size == 0 {
+			return nil
+		}
+		return node
+	}
+	if i < node.left.size {
+		node.left = node.left.delete(i)
+		if node.left != nil && node.left.isUnderfull() {
+			return node.fixLeaf(true)
+		}
+	} else {
+		node.right = node.right.delete(i - node.left.size)
+		if node.right != nil && node.right.isUnderfull() {
+			return node.fixLeaf(false)
+		}
+	}
+	if node.left == nil {
+		return node.right
+	}
+	if node.right == nil {
+		return node.left
+	}
+	return node.balance()
+}
+
+// isUnderfull returns true if the node is a leaf that holds less than half of its capacity.
+func (node *dynamicNode) isUnderfull() bool {
+	return node.left == nil && node.size < dynamicLeafBits/2
+}
+
+// fixLeaf merges the underfull leaf child with the adjacent leaf in its sibling, or moves bits from the adjacent leaf
+// if they do not fit in a leaf. `leafIsLeft` indicates which child is the underfull leaf. It returns new root of the subtree.
+func (node *dynamicNode) fixLeaf(leafIsLeft bool) *dynamicNode {
+	var leaf, sibling = node.left, node.right
+	if !leafIsLeft {
+		leaf, sibling = node.right, node.left
+	}
+	var neighbor = sibling.edgeLeaf(leafIsLeft)
+	var first, second = leaf, neighbor
+	if !leafIsLeft {
+		first, second = neighbor, leaf
+	}
+	joined := new(BitVectorData)
+	joined.blocks = make([]uint64, 0, dynamicLeafBlocks*2)
+	for _, part := range []*dynamicNode{first, second} {
+		for j, block := range part.blocks {
+			var length = part.size - uint64(j)*sBlockSize
+			if length > sBlockSize {
+				length = sBlockSize
+			}
+			joined.pushBackBits(mask(block, length), length)
+		}
+	}
+	if joined.size < dynamicLeafBits {
+		neighbor.setLeaf(joined.blocks, joined.size)
+		sibling.updateEdge(leafIsLeft)
+		return sibling
+	}
+	var half = joined.size / 2 / sBlockSize
+	firstBlocks := make([]uint64, half, dynamicLeafBlocks)
+	copy(firstBlocks, joined.blocks[:half])
+	secondBlocks := make([]uint64, uint64(len(joined.blocks))-half, dynamicLeafBlocks)
+	copy(secondBlocks, joined.blocks[half:])
+	first.setLeaf(firstBlocks, half*sBlockSize)
+	second.setLeaf(secondBlocks, joined.size-half*sBlockSize)
+	sibling.updateEdge(leafIsLeft)
+	return node.balance()
+}
+
+// edgeLeaf returns the leftmost leaf of the subtree if `leftmost` is true, otherwise the rightmost leaf.
+func (node *dynamicNode) edgeLeaf(leftmost bool) *dynamicNode {
+	for node.left != nil {
+		if leftmost {
+			node = node.left
+		} else {
+			node = node.right
+		}
+	}
+	return node
+}
+
+// updateEdge recomputes the fields of the internal nodes on the path to the leftmost or the rightmost leaf.
+func (node *dynamicNode) updateEdge(leftmost bool) {
+	if node.left == nil {
+		return
+	}
+	if leftmost {
+		node.left.updateEdge(leftmost)
+	} else {
+		node.right.updateEdge(leftmost)
+	}
+	node.update()
+}
+
+// setLeaf replaces the bits of the leaf by `length` bits in `blocks`.
+func (node *dynamicNode) setLeaf(blocks []uint64, length uint64) {
+	*node = *newDynamicLeaf(blocks, length)
+}
+
+// insertBit inserts the bit `b` at position `i` of the leaf.
+func (node *dynamicNode) insertBit(i uint64, b bool) {
+	if node.size%sBlockSize == 0 {
+		node.blocks = append(node.blocks, 0)
+	}
+	var blockID = i / sBlockSize
+	var r = i % sBlockSize
+	for j := len(node.blocks) - 1; j > int(blockID); j-- {
+		node.blocks[j] = (node.blocks[j] << 1) | (node.blocks[j-1] >> (sBlockSize - 1))
+	}
+	var x = node.blocks[blockID]
+	var lower = mask(x, r)
+	x = lower | ((x &^ lower) << 1)
+	if b {
+		x |= 1 << r
+		node.numOf1s++
+	}
+	node.blocks[blockID] = x
+	node.size++
+}
+
+// deleteBit removes the i-th bit of the leaf.
+func (node *dynamicNode) deleteBit(i uint64) {
+	var blockID = i / sBlockSize
+	var r = i % sBlockSize
+	var x = node.blocks[blockID]
+	if (x>>r)&1 == 1 {
+		node.numOf1s--
+	}
+	var lower = mask(^uint64(0), r)
+	x = (x & lower) | ((x >> 1) &^ lower)
+	if int(blockID)+1 < len(node.blocks) {
+		x |= node.blocks[blockID+1] << (sBlockSize - 1)
+	}
+	node.blocks[blockID] = x
+	for j := int(blockID) + 1; j < len(node.blocks); j++ {
+		node.blocks[j] >>= 1
+		if j+1 < len(node.blocks) {
+			node.blocks[j] |= node.blocks[j+1] << (sBlockSize - 1)
+		}
+	}
+	node.size--
+	if node.size%sBlockSize == 0 {
+		node.blocks = node.blocks[:len(node.blocks)-1]
+	}
+}
+
+// split divides the full leaf into two leaves, and returns internal node that holds them.
+func (node *dynamicNode) split() *dynamicNode {
+	var half = len(node.blocks) / 2
+	leftBlocks := make([]uint64, half, dynamicLeafBlocks)
+	copy(leftBlocks, node.blocks[:half])
+	rightBlocks := make([]uint64, len(node.blocks)-half, dynamicLeafBlocks)
+	copy(rightBlocks, node.blocks[half:])
+	parent := &dynamicNode{
+		left:  newDynamicLeaf(leftBlocks, uint64(half)*sBlockSize),
+		right: newDynamicLeaf(rightBlocks, node.size-uint64(half)*sBlockSize),
+	}
+	parent.update()
+	return parent
+}
+
+// balance restores the AVL condition of the internal node, and returns new root of the subtree.
+func (node *dynamicNode) balance() *dynamicNode {
+	node.update()
+	if node.left.height > node.right.height+1 {
+		if node.left.right.height > node.left.left.height {
+			node.left = node.left.rotateLeft()
+		}
+		return node.rotateRight()
+	}
+	if node.right.height > node.left.height+1 {
+		if node.right.left.height > node.right.right.height {
+			node.right = node.right.rotateRight()
+		}
+		return node.rotateLeft()
+	}
+	return node
+}
+
+func (node *dynamicNode) rotateLeft() *dynamicNode {
+	var top = node.right
+	node.right = top.left
+	node.update()
+	top.left = node
+	top.update()
+	return top
+}
+
+func (node *dynamicNode) rotateRight() *dynamicNode {
+	var top = node.left
+	node.left = top.right
+	node.update()
+	top.right = node
+	top.update()
+	return top
+}
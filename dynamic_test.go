@@ -0,0 +1,179 @@
+package sbvector
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDynamicVector(t *testing.T) {
+	vec := NewDynamicVector()
+	if vec.Size() != 0 || vec.NumOfBits(true) != 0 {
+		t.Error("Expected", 0, "got", vec.Size())
+	}
+	if _, err := vec.Get(0); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+	if err := vec.Insert(1, true); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+	if err := vec.Delete(0); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	var bits []bool
+	for n := 0; n < 30000; n++ {
+		var op = r.Intn(10)
+		switch {
+		case op < 6 || len(bits) == 0:
+			var i = r.Intn(len(bits) + 1)
+			var b = r.Intn(3) == 0
+			if err := vec.Insert(uint64(i), b); err != nil {
+				t.Fatal(err)
+			}
+			bits = append(bits, false)
+			copy(bits[i+1:], bits[i:])
+			bits[i] = b
+		case op < 8:
+			var i = r.Intn(len(bits))
+			if err := vec.Delete(uint64(i)); err != nil {
+				t.Fatal(err)
+			}
+			bits = append(bits[:i], bits[i+1:]...)
+		default:
+			var i = r.Intn(len(bits))
+			var b = r.Intn(2) == 0
+			if err := vec.Set(uint64(i), b); err != nil {
+				t.Fatal(err)
+			}
+			bits[i] = b
+		}
+	}
+
+	builder := NewVectorBuilder()
+	for _, b := range bits {
+		builder.PushBack(b)
+	}
+	expected, _ := builder.Build(true, true)
+	compareDynamicVector(t, expected, vec)
+
+	built, err := vec.Build(true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareVectors(t, expected, built)
+
+	vec2, err := NewDynamicVectorFromVector(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareDynamicVector(t, expected, vec2)
+	for i := 0; i < 1000; i++ {
+		vec2.PushBack(true)
+		bits = append(bits, true)
+	}
+	for _, b := range bits {
+		builder.PushBack(b)
+	}
+	expected, _ = builder.Build(false, false)
+	compareDynamicVector(t, expected, vec2)
+
+	for vec.Size() > 0 {
+		if err := vec.Delete(vec.Size() - 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if vec.Size() != 0 || vec.NumOfBits(true) != 0 {
+		t.Error("Expected", 0, "got", vec.Size(), vec.NumOfBits(true))
+	}
+}
+
+// compareDynamicVector checks that `vec` answers same as `expected` for every query.
+func compareDynamicVector(t *testing.T, expected SuccinctBitVector, vec *DynamicVectorData) {
+	if expected.Size() != vec.Size() || expected.NumOfBits(true) != vec.NumOfBits(true) {
+		t.Fatal("Expected", expected.Size(), expected.NumOfBits(true), "got", vec.Size(), vec.NumOfBits(true))
+	}
+	for i := uint64(0); i < expected.Size(); i++ {
+		b1, _ := expected.Get(i)
+		b2, err := vec.Get(i)
+		if err != nil || b1 != b2 {
+			t.Fatal("Get", i, "Expected", b1, "got", b2)
+		}
+	}
+	for i := uint64(0); i <= expected.Size(); i++ {
+		r1, _ := expected.Rank0(i)
+		r2, err := vec.Rank0(i)
+		if err != nil || r1 != r2 {
+			t.Fatal("Rank0", i, "Expected", r1, "got", r2)
+		}
+	}
+	for x := uint64(0); x < expected.NumOfBits(true); x++ {
+		p1, _ := expected.Select1(x)
+		p2, err := vec.Select1(x)
+		if err != nil || p1 != p2 {
+			t.Fatal("Select1", x, "Expected", p1, "got", p2)
+		}
+	}
+	for x := uint64(0); x < expected.NumOfBits(false); x++ {
+		p1, _ := expected.Select0(x)
+		p2, err := vec.Select0(x)
+		if err != nil || p1 != p2 {
+			t.Fatal("Select0", x, "Expected", p1, "got", p2)
+		}
+	}
+	if _, err := vec.Rank1(expected.Size() + 1); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+	if _, err := vec.Select1(expected.NumOfBits(true)); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+}
+
+func TestDynamicVectorMergeLeaves(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	var bits []bool
+	builder := NewVectorBuilder()
+	for i := 0; i < 100000; i++ {
+		var b = r.Intn(2) == 0
+		bits = append(bits, b)
+		builder.PushBack(b)
+	}
+	src, _ := builder.Build(false, false)
+	vec, _ := NewDynamicVectorFromVector(src)
+	for len(bits) > 5000 {
+		var i = r.Intn(len(bits))
+		if err := vec.Delete(uint64(i)); err != nil {
+			t.Fatal(err)
+		}
+		bits = append(bits[:i], bits[i+1:]...)
+	}
+
+	var numOfLeaves uint64
+	vec.root.each(func(leaf *dynamicNode) {
+		numOfLeaves++
+	})
+	if max := uint64(len(bits))/(dynamicLeafBits/2) + 2; numOfLeaves > max {
+		t.Error("Expected", max, "leaves at most, got", numOfLeaves)
+	}
+	checkDynamicNode(t, vec.root)
+	for _, b := range bits {
+		builder.PushBack(b)
+	}
+	expected, _ := builder.Build(true, true)
+	compareDynamicVector(t, expected, vec)
+}
+
+// checkDynamicNode checks the fields and the AVL condition of the subtree.
+func checkDynamicNode(t *testing.T, node *dynamicNode) {
+	if node.left == nil {
+		return
+	}
+	checkDynamicNode(t, node.left)
+	checkDynamicNode(t, node.right)
+	if node.size != node.left.size+node.right.size || node.numOf1s != node.left.numOf1s+node.right.numOf1s {
+		t.Fatal("Expected", node.left.size+node.right.size, node.left.numOf1s+node.right.numOf1s, "got", node.size, node.numOf1s)
+	}
+	if d := node.left.height - node.right.height; d > 1 || d < -1 {
+		t.Fatal("Unbalanced node", node.left.height, node.right.height)
+	}
+}
@@ -0,0 +1,210 @@
+package sbvector
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// ErrorNotSorted indicates that values are not sorted.
+var ErrorNotSorted = errors.New("Values are not sorted")
+
+// EliasFano holds monotone sequence encoded by Elias-Fano encoding.
+//
+// Each value is split into upper bits and lower `lowWidth` bits.
+// The upper bits are stored as unary code in `upper`, and the lower bits are packed in `lower`.
+type EliasFano struct {
+	upper    BitVectorData
+	lower    BitVectorData
+	lowWidth uint64
+	length   uint64
+}
+
+// NewEliasFano returns Elias-Fano encoded sequence of `values`.
+// `values` must be sorted in ascending order, and must not contain NotFound.
+func NewEliasFano(values []uint64) (*EliasFano, error) {
+	var universe uint64
+	for i, v := range values {
+		if i > 0 && v < values[i-1] {
+			return nil, ErrorNotSorted
+		}
+		if v == NotFound {
+			return nil, ErrorOutOfRange
+		}
+		universe = v + 1
+	}
+	return newEliasFano(values, universe), nil
+}
+
+// newEliasFano returns Elias-Fano encoded sequence of `values`.
+// `values` must be sorted, and each value must be less than `universe`.
+func newEliasFano(values []uint64, universe uint64) *EliasFano {
+	ef := new(EliasFano)
+	ef.length = uint64(len(values))
+	if ef.length > 0 && universe > ef.length {
+		ef.lowWidth = bitWidth(universe/ef.length) - 1
+	}
+	ef.upper.set(ef.length+(universe>>ef.lowWidth), false)
+	for i, v := range values {
+		ef.upper.set((v>>ef.lowWidth)+uint64(i), true)
+		if ef.lowWidth > 0 {
+			ef.lower.pushBackBits(mask(v, ef.lowWidth), ef.lowWidth)
+		}
+	}
+	ef.upper.build(true, true)
+	return ef
+}
+
+// Len returns number of values in the sequence.
+func (ef *EliasFano) Len() uint64 {
+	return ef.length
+}
+
+// Access returns the i-th value.
+func (ef *EliasFano) Access(i uint64) (uint64, error) {
+	if i >= ef.length {
+		return NotFound, ErrorOutOfRange
+	}
+	return ef.access(i), nil
+}
+
+// NextGEQ returns the smallest value that is not less than `x`.
+// If there is no such value, returns NotFound.
+func (ef *EliasFano) NextGEQ(x uint64) (uint64, error) {
+	var i = ef.lowerBound(x)
+	if i >= ef.length {
+		return NotFound, nil
+	}
+	return ef.access(i), nil
+}
+
+// Iterator returns new iterator over values from the i-th.
+func (ef *EliasFano) Iterator(i uint64) *EliasFanoIterator {
+	it := &EliasFanoIterator{ef: ef, index: i}
+	if i < ef.length {
+		it.pos, _ = ef.upper.Select1(i)
+	}
+	return it
+}
+
+// EliasFanoIterator iterates values of EliasFano in ascending order.
+type EliasFanoIterator struct {
+	ef    *EliasFano
+	index uint64
+	pos   uint64
+}
+
+// Next returns the next value. If there are no more values, returns false.
+func (it *EliasFanoIterator) Next() (uint64, bool) {
+	if it.index >= it.ef.length {
+		return NotFound, false
+	}
+	it.pos, _ = it.ef.upper.NextOne(it.pos)
+	var v = ((it.pos - it.index) << it.ef.lowWidth) | it.ef.low(it.index)
+	it.index++
+	it.pos++
+	return v, true
+}
+
+// low returns lower bits of the i-th value.
+func (ef *EliasFano) low(i uint64) uint64 {
+	if ef.lowWidth == 0 {
+		return 0
+	}
+	x, _ := ef.lower.GetBits(i*ef.lowWidth, ef.lowWidth)
+	return x
+}
+
+// access returns the i-th value.
+func (ef *EliasFano) access(i uint64) uint64 {
+	pos, _ := ef.upper.Select1(i)
+	return ((pos - i) << ef.lowWidth) | ef.low(i)
+}
+
+// lowerBound returns index of the first value that is not less than `x`.
+func (ef *EliasFano) lowerBound(x uint64) uint64 {
+	var high = x >> ef.lowWidth
+	if high >= ef.upper.NumOfBits(false) {
+		return ef.length
+	}
+	var low = mask(x, ef.lowWidth)
+	var i uint64
+	var pos uint64
+	if high > 0 {
+		pos, _ = ef.upper.Select0(high - 1)
+		i = pos - (high - 1)
+		pos++
+	}
+	for i < ef.length {
+		b, _ := ef.upper.Get(pos)
+		if !b || ef.low(i) >= low {
+			break
+		}
+		i++
+		pos++
+	}
+	return i
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (ef *EliasFano) MarshalBinary() ([]byte, error) {
+	buf, err := ef.marshalPayload()
+	if err != nil {
+		return nil, err
+	}
+	return marshalContainer(kindEliasFano, 0, buf), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (ef *EliasFano) UnmarshalBinary(data []byte) error {
+	_, payload, err := unmarshalContainer(data, kindEliasFano, true)
+	if err != nil {
+		return err
+	}
+	buf, err := ef.unmarshal(payload)
+	if err != nil {
+		return err
+	}
+	if len(buf) != 0 {
+		return ErrorInvalidFormat
+	}
+	return nil
+}
+
+// marshalPayload returns the image of the sequence that is embedded in other images.
+func (ef *EliasFano) marshalPayload() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	binary.Write(buffer, binary.LittleEndian, &ef.lowWidth)
+	for _, v := range []*BitVectorData{&ef.upper, &ef.lower} {
+		buf, err := v.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(buf)
+	}
+	return buffer.Bytes(), nil
+}
+
+// unmarshal restores the sequence from the head of `data`, and returns the rest of `data`.
+func (ef *EliasFano) unmarshal(data []byte) ([]byte, error) {
+	if uint64(len(data)) < sizeOfInt64 {
+		return nil, ErrorInvalidLength
+	}
+	ef.lowWidth = binary.LittleEndian.Uint64(data)
+	if ef.lowWidth >= sBlockSize {
+		return nil, ErrorInvalidFormat
+	}
+	buf, err := unmarshalEmbedded(&ef.upper, data[sizeOfInt64:])
+	if err != nil {
+		return nil, err
+	}
+	buf, err = unmarshalEmbedded(&ef.lower, buf)
+	if err != nil {
+		return nil, err
+	}
+	ef.length = ef.upper.NumOfBits(true)
+	if ef.lower.size != ef.length*ef.lowWidth || len(ef.upper.ranks) == 0 {
+		return nil, ErrorInvalidFormat
+	}
+	return buf, nil
+}
@@ -0,0 +1,91 @@
+package sbvector
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestEliasFano(t *testing.T) {
+	if _, err := NewEliasFano([]uint64{1, 3, 2}); err != ErrorNotSorted {
+		t.Error("Expected", ErrorNotSorted, "got", err)
+	}
+	if _, err := NewEliasFano([]uint64{1, NotFound}); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+
+	ef, err := NewEliasFano(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := ef.NextGEQ(0); err != nil || v != NotFound {
+		t.Error("Expected", NotFound, "got", v)
+	}
+	if _, ok := ef.Iterator(0).Next(); ok {
+		t.Error("Expected", false, "got", ok)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for _, universe := range []int64{1000, 1 << 20, 1 << 62} {
+		var values = make([]uint64, 3000)
+		var v uint64
+		for i := range values {
+			v += uint64(r.Int63n(universe/1000 + 1))
+			values[i] = v
+		}
+		ef, err := NewEliasFano(values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkEliasFano(t, ef, values, r)
+
+		buf, err := ef.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ef2 := new(EliasFano)
+		if err := ef2.UnmarshalBinary(buf); err != nil {
+			t.Fatal(err)
+		}
+		checkEliasFano(t, ef2, values, r)
+		if err := ef2.UnmarshalBinary(buf[:len(buf)-1]); err == nil {
+			t.Error("Expected error")
+		}
+	}
+}
+
+// checkEliasFano checks that `ef` holds `values`.
+func checkEliasFano(t *testing.T, ef *EliasFano, values []uint64, r *rand.Rand) {
+	if ef.Len() != uint64(len(values)) {
+		t.Fatal("Expected", len(values), "got", ef.Len())
+	}
+	for i, v := range values {
+		if x, err := ef.Access(uint64(i)); err != nil || x != v {
+			t.Fatal("Access", i, "Expected", v, "got", x)
+		}
+	}
+	if _, err := ef.Access(ef.Len()); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+	it := ef.Iterator(10)
+	for i := 10; i < len(values); i++ {
+		if x, ok := it.Next(); !ok || x != values[i] {
+			t.Fatal("Next", i, "Expected", values[i], "got", x)
+		}
+	}
+	if _, ok := it.Next(); ok {
+		t.Error("Expected", false, "got", ok)
+	}
+	var last = values[len(values)-1]
+	for k := 0; k < 1000; k++ {
+		var x = uint64(r.Int63n(int64(last) + 2))
+		var i = sort.Search(len(values), func(i int) bool { return values[i] >= x })
+		var expected = NotFound
+		if i < len(values) {
+			expected = values[i]
+		}
+		if v, err := ef.NextGEQ(x); err != nil || v != expected {
+			t.Fatal("NextGEQ", x, "Expected", expected, "got", v)
+		}
+	}
+}
@@ -0,0 +1,409 @@
+// Package fmindex implements FM-index for substring search over byte strings.
+// The Burrows-Wheeler transform of the text is held by sbvector.WaveletMatrix,
+// and positions of the occurrences are computed from the sampled suffix array.
+package fmindex
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"github.com/hideo55/go-sbvector"
+)
+
+// DefaultSampleRate is the sample rate of the suffix array used when 0 is given to New.
+const DefaultSampleRate uint64 = 32
+
+const (
+	sizeOfInt64 = 8
+	// alphabetSize is number of symbols, the terminator `0` and the bytes shifted by one.
+	alphabetSize = 257
+)
+
+// Index is FM-index of a byte string.
+type Index struct {
+	bwt        *sbvector.WaveletMatrix
+	counts     [alphabetSize + 1]uint64
+	sampled    sbvector.SuccinctBitVector
+	samples    *sbvector.IntVector
+	inverse    *sbvector.IntVector
+	sampleRate uint64
+	length     uint64
+}
+
+// New returns new FM-index of `text`.
+// Every `sampleRate`-th position of the text is sampled from the suffix array, and its inverse.
+// Larger rate makes the index smaller and Locate/Extract slower. If `sampleRate` is 0, DefaultSampleRate is used.
+func New(text []byte, sampleRate uint64) (*Index, error) {
+	if sampleRate == 0 {
+		sampleRate = DefaultSampleRate
+	}
+	var n = uint64(len(text))
+	symbols := make([]int, n+1)
+	for i, c := range text {
+		symbols[i] = int(c) + 1
+	}
+	sa := make([]int, n+1)
+	suffixArray(symbols, sa, alphabetSize)
+
+	idx := &Index{sampleRate: sampleRate, length: n}
+	var err error
+	idx.samples, err = sbvector.NewIntVector(width(n / sampleRate))
+	if err != nil {
+		return nil, err
+	}
+	idx.inverse, err = sbvector.NewIntVector(width(n))
+	if err != nil {
+		return nil, err
+	}
+	bwt := make([]uint64, n+1)
+	inverse := make([]uint64, n/sampleRate+1)
+	builder := sbvector.NewVectorBuilder()
+	for row, pos := range sa {
+		if pos > 0 {
+			bwt[row] = uint64(symbols[pos-1])
+		}
+		if uint64(pos)%sampleRate == 0 {
+			builder.PushBack(true)
+			idx.samples.Append(uint64(pos) / sampleRate)
+			inverse[uint64(pos)/sampleRate] = uint64(row)
+		} else {
+			builder.PushBack(false)
+		}
+	}
+	for _, row := range inverse {
+		idx.inverse.Append(row)
+	}
+	idx.sampled, err = builder.Build(false, false)
+	if err != nil {
+		return nil, err
+	}
+	idx.bwt, err = sbvector.NewWaveletMatrix(bwt)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range bwt {
+		idx.counts[c+1]++
+	}
+	for c := 1; c <= alphabetSize; c++ {
+		idx.counts[c] += idx.counts[c-1]
+	}
+	return idx, nil
+}
+
+// Len returns length of the text.
+func (idx *Index) Len() uint64 {
+	return idx.length
+}
+
+// Count returns number of the occurrences of `pattern` in the text.
+func (idx *Index) Count(pattern []byte) uint64 {
+	begin, end := idx.search(pattern)
+	return end - begin
+}
+
+// Locate returns the positions of the occurrences of `pattern` in the text, in ascending order.
+func (idx *Index) Locate(pattern []byte) []uint64 {
+	begin, end := idx.search(pattern)
+	positions := make([]uint64, 0, end-begin)
+	for row := begin; row < end; row++ {
+		positions = append(positions, idx.locate(row))
+	}
+	sort.Sort(uint64Slice(positions))
+	return positions
+}
+
+// Extract returns the substring of the text in range [i, j).
+func (idx *Index) Extract(i uint64, j uint64) ([]byte, error) {
+	if i > j || j > idx.length {
+		return nil, sbvector.ErrorOutOfRange
+	}
+	// Walk backward from the sampled position at or after `j`; the end of the text is always row 0.
+	var pos = (j + idx.sampleRate - 1) / idx.sampleRate * idx.sampleRate
+	var row uint64
+	if pos < idx.length {
+		row, _ = idx.inverse.Get(pos / idx.sampleRate)
+	} else {
+		pos = idx.length
+	}
+	result := make([]byte, j-i)
+	for ; pos > i; pos-- {
+		c, _ := idx.bwt.Access(row)
+		if pos <= j {
+			result[pos-1-i] = byte(c - 1)
+		}
+		row = idx.lf(c, row)
+	}
+	return result, nil
+}
+
+// search returns the range of the rows of the suffixes that start with `pattern`.
+func (idx *Index) search(pattern []byte) (uint64, uint64) {
+	var begin, end = uint64(0), idx.length + 1
+	for k := len(pattern) - 1; k >= 0 && begin < end; k-- {
+		var c = uint64(pattern[k]) + 1
+		begin = idx.lf(c, begin)
+		end = idx.lf(c, end)
+	}
+	return begin, end
+}
+
+// lf returns number of the rows whose suffixes are less than the suffix `c` followed by the suffix of `row`.
+func (idx *Index) lf(c uint64, row uint64) uint64 {
+	rank, _ := idx.bwt.Rank(c, row)
+	return idx.counts[c] + rank
+}
+
+// locate returns the position in the text of the suffix of `row`.
+func (idx *Index) locate(row uint64) uint64 {
+	var steps uint64
+	for {
+		if b, _ := idx.sampled.Get(row); b {
+			rank, _ := idx.sampled.Rank1(row)
+			pos, _ := idx.samples.Get(rank)
+			return pos*idx.sampleRate + steps
+		}
+		c, _ := idx.bwt.Access(row)
+		row = idx.lf(c, row)
+		steps++
+	}
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The image holds the length of the text and the sample rate, followed by the size and the image of each component.
+// The images of the components carry their own checksums.
+func (idx *Index) MarshalBinary() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	binary.Write(buffer, binary.LittleEndian, &idx.length)
+	binary.Write(buffer, binary.LittleEndian, &idx.sampleRate)
+	for _, m := range []interface {
+		MarshalBinary() ([]byte, error)
+	}{idx.bwt, idx.sampled, idx.samples, idx.inverse} {
+		buf, err := m.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		var size = uint64(len(buf))
+		binary.Write(buffer, binary.LittleEndian, &size)
+		buffer.Write(buf)
+	}
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (idx *Index) UnmarshalBinary(data []byte) error {
+	if len(data) < sizeOfInt64*2 {
+		return sbvector.ErrorInvalidLength
+	}
+	idx.length = binary.LittleEndian.Uint64(data)
+	idx.sampleRate = binary.LittleEndian.Uint64(data[sizeOfInt64:])
+	if idx.sampleRate == 0 {
+		return sbvector.ErrorInvalidFormat
+	}
+	var parts [4][]byte
+	var buf = data[sizeOfInt64*2:]
+	for k := range parts {
+		if len(buf) < sizeOfInt64 {
+			return sbvector.ErrorInvalidLength
+		}
+		var size = binary.LittleEndian.Uint64(buf)
+		buf = buf[sizeOfInt64:]
+		if size > uint64(len(buf)) {
+			return sbvector.ErrorInvalidLength
+		}
+		parts[k] = buf[:size]
+		buf = buf[size:]
+	}
+	if len(buf) != 0 {
+		return sbvector.ErrorInvalidFormat
+	}
+	idx.bwt = new(sbvector.WaveletMatrix)
+	if err := idx.bwt.UnmarshalBinary(parts[0]); err != nil {
+		return err
+	}
+	sampled, err := sbvector.NewVectorFromBinary(parts[1])
+	if err != nil {
+		return err
+	}
+	idx.sampled = sampled
+	idx.samples = new(sbvector.IntVector)
+	if err := idx.samples.UnmarshalBinary(parts[2]); err != nil {
+		return err
+	}
+	idx.inverse = new(sbvector.IntVector)
+	if err := idx.inverse.UnmarshalBinary(parts[3]); err != nil {
+		return err
+	}
+	var n = idx.length + 1
+	if idx.bwt.Len() != n || idx.sampled.Size() != n || idx.samples.Len() != idx.sampled.NumOfBits(true) ||
+		idx.inverse.Len() != idx.length/idx.sampleRate+1 {
+		return sbvector.ErrorInvalidFormat
+	}
+	idx.counts = [alphabetSize + 1]uint64{}
+	for c := uint64(0); c < alphabetSize; c++ {
+		rank, _ := idx.bwt.Rank(c, n)
+		idx.counts[c+1] = idx.counts[c] + rank
+	}
+	if idx.counts[alphabetSize] != n || idx.counts[1] != 1 {
+		return sbvector.ErrorInvalidFormat
+	}
+	return nil
+}
+
+// suffixArray stores the suffix array of `s` into `sa` by SA-IS algorithm in O(n) time.
+// Symbols of `s` must be less than `k`, and the last symbol must be the unique smallest symbol `0`.
+func suffixArray(s []int, sa []int, k int) {
+	var n = len(s)
+	if n == 1 {
+		sa[0] = 0
+		return
+	}
+	// A suffix is S-type if it is smaller than the next suffix, otherwise L-type.
+	stype := make([]bool, n)
+	stype[n-1] = true
+	for i := n - 2; i >= 0; i-- {
+		stype[i] = s[i] < s[i+1] || (s[i] == s[i+1] && stype[i+1])
+	}
+	isLMS := func(i int) bool {
+		return i > 0 && stype[i] && !stype[i-1]
+	}
+	bucket := make([]int, k)
+	buckets := func(end bool) {
+		for c := range bucket {
+			bucket[c] = 0
+		}
+		for _, c := range s {
+			bucket[c]++
+		}
+		var sum int
+		for c := range bucket {
+			sum += bucket[c]
+			if end {
+				bucket[c] = sum
+			} else {
+				bucket[c] = sum - bucket[c]
+			}
+		}
+	}
+	// induce sorts L-type suffixes from the LMS suffixes, then S-type suffixes from the L-type suffixes.
+	induce := func() {
+		buckets(false)
+		for i := 0; i < n; i++ {
+			if j := sa[i] - 1; j >= 0 && !stype[j] {
+				sa[bucket[s[j]]] = j
+				bucket[s[j]]++
+			}
+		}
+		buckets(true)
+		for i := n - 1; i >= 0; i-- {
+			if j := sa[i] - 1; j >= 0 && stype[j] {
+				bucket[s[j]]--
+				sa[bucket[s[j]]] = j
+			}
+		}
+	}
+
+	// Sort the LMS substrings.
+	for i := range sa {
+		sa[i] = -1
+	}
+	buckets(true)
+	for i := 1; i < n; i++ {
+		if isLMS(i) {
+			bucket[s[i]]--
+			sa[bucket[s[i]]] = i
+		}
+	}
+	induce()
+
+	// Name the LMS substrings by their order, and store the names in the latter half of `sa`.
+	var n1 int
+	for i := 0; i < n; i++ {
+		if isLMS(sa[i]) {
+			sa[n1] = sa[i]
+			n1++
+		}
+	}
+	for i := n1; i < n; i++ {
+		sa[i] = -1
+	}
+	var name, prev = 0, -1
+	for i := 0; i < n1; i++ {
+		var pos = sa[i]
+		var diff = prev < 0
+		for d := 0; !diff; d++ {
+			if s[pos+d] != s[prev+d] || stype[pos+d] != stype[prev+d] {
+				diff = true
+			} else if d > 0 && (isLMS(pos+d) || isLMS(prev+d)) {
+				break
+			}
+		}
+		if diff {
+			name++
+			prev = pos
+		}
+		sa[n1+pos/2] = name - 1
+	}
+	for i, j := n-1, n-1; i >= n1; i-- {
+		if sa[i] >= 0 {
+			sa[j] = sa[i]
+			j--
+		}
+	}
+
+	// Sort the LMS suffixes by the suffix array of the names.
+	s1 := sa[n-n1:]
+	sa1 := sa[:n1]
+	if name < n1 {
+		suffixArray(s1, sa1, name)
+	} else {
+		for i, c := range s1 {
+			sa1[c] = i
+		}
+	}
+	var j int
+	for i := 1; i < n; i++ {
+		if isLMS(i) {
+			s1[j] = i
+			j++
+		}
+	}
+	for i := range sa1 {
+		sa1[i] = s1[sa1[i]]
+	}
+	for i := n1; i < n; i++ {
+		sa[i] = -1
+	}
+	buckets(true)
+	for i := n1 - 1; i >= 0; i-- {
+		var pos = sa[i]
+		sa[i] = -1
+		bucket[s[pos]]--
+		sa[bucket[s[pos]]] = pos
+	}
+	induce()
+}
+
+// uint64Slice sorts uint64 values in ascending order.
+type uint64Slice []uint64
+
+func (s uint64Slice) Len() int {
+	return len(s)
+}
+
+func (s uint64Slice) Less(i, j int) bool {
+	return s[i] < s[j]
+}
+
+func (s uint64Slice) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// width returns number of bits to represent `x`, at least 1.
+func width(x uint64) uint64 {
+	var w = uint64(1)
+	for w < 64 && x>>w != 0 {
+		w++
+	}
+	return w
+}
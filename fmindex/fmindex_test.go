@@ -0,0 +1,167 @@
+package fmindex
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/hideo55/go-sbvector"
+)
+
+// naiveLocate returns the positions of `pattern` in `text` by brute force.
+func naiveLocate(text []byte, pattern []byte) []uint64 {
+	var positions []uint64
+	for i := 0; i+len(pattern) <= len(text); i++ {
+		if bytes.Equal(text[i:i+len(pattern)], pattern) {
+			positions = append(positions, uint64(i))
+		}
+	}
+	return positions
+}
+
+// suffixSorter sorts suffixes of the text by comparing them directly.
+type suffixSorter struct {
+	text []byte
+	sa   []int
+}
+
+func (s suffixSorter) Len() int {
+	return len(s.sa)
+}
+
+func (s suffixSorter) Less(i, j int) bool {
+	return bytes.Compare(s.text[s.sa[i]:], s.text[s.sa[j]:]) < 0
+}
+
+func (s suffixSorter) Swap(i, j int) {
+	s.sa[i], s.sa[j] = s.sa[j], s.sa[i]
+}
+
+func TestSuffixArray(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	texts := [][]byte{nil, []byte("a"), []byte("aaaaaaaaaa"), []byte("abababababab"), []byte("mississippi")}
+	for k := 0; k < 50; k++ {
+		text := make([]byte, r.Intn(500))
+		for i := range text {
+			text[i] = byte('a' + r.Intn(1+k%4))
+		}
+		texts = append(texts, text)
+	}
+	for _, text := range texts {
+		symbols := make([]int, len(text)+1)
+		for i, c := range text {
+			symbols[i] = int(c) + 1
+		}
+		sa := make([]int, len(symbols))
+		suffixArray(symbols, sa, alphabetSize)
+
+		// The terminator is the smallest, so the empty suffix comes first.
+		expected := make([]int, len(text)+1)
+		for i := range expected {
+			expected[i] = i
+		}
+		sort.Sort(suffixSorter{text, expected[:len(text)]})
+		expected = append([]int{len(text)}, expected[:len(text)]...)
+		for i := range expected {
+			if sa[i] != expected[i] {
+				t.Fatal("Expected", expected, "got", sa)
+			}
+		}
+	}
+}
+
+func checkIndex(t *testing.T, idx *Index, text []byte, patterns [][]byte) {
+	if idx.Len() != uint64(len(text)) {
+		t.Fatal("Expected", len(text), "got", idx.Len())
+	}
+	for _, pattern := range patterns {
+		expected := naiveLocate(text, pattern)
+		if n := idx.Count(pattern); n != uint64(len(expected)) {
+			t.Error("Count", string(pattern), "Expected", len(expected), "got", n)
+		}
+		positions := idx.Locate(pattern)
+		if len(positions) != len(expected) {
+			t.Fatal("Locate", string(pattern), "Expected", expected, "got", positions)
+		}
+		for k := range expected {
+			if positions[k] != expected[k] {
+				t.Fatal("Locate", string(pattern), "Expected", expected, "got", positions)
+			}
+		}
+	}
+	for i := 0; i <= len(text); i++ {
+		for j := i; j <= len(text) && j <= i+40; j++ {
+			s, err := idx.Extract(uint64(i), uint64(j))
+			if err != nil || !bytes.Equal(s, text[i:j]) {
+				t.Fatal("Extract", i, j, "Expected", text[i:j], "got", s)
+			}
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	text := []byte("abracadabra mississippi banana\x00\xffabra")
+	patterns := [][]byte{[]byte("a"), []byte("abra"), []byte("issi"), []byte("ana"), []byte("\x00\xff"), []byte("xyz"), []byte("abracadabra mississippi banana\x00\xffabra!")}
+	for _, rate := range []uint64{0, 1, 3, 64} {
+		idx, err := New(text, rate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkIndex(t, idx, text, patterns)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	text = make([]byte, 3000)
+	for i := range text {
+		text[i] = "acgt"[r.Intn(4)]
+	}
+	patterns = nil
+	for k := 0; k < 50; k++ {
+		var i = r.Intn(len(text) - 10)
+		patterns = append(patterns, text[i:i+1+r.Intn(10)])
+	}
+	idx, err := New(text, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkIndex(t, idx, text, patterns)
+
+	if _, err := idx.Extract(10, 3001); err != sbvector.ErrorOutOfRange {
+		t.Error("Expected", sbvector.ErrorOutOfRange, "got", err)
+	}
+	if _, err := idx.Extract(10, 9); err != sbvector.ErrorOutOfRange {
+		t.Error("Expected", sbvector.ErrorOutOfRange, "got", err)
+	}
+
+	buf, err := idx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx2 := new(Index)
+	if err := idx2.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	checkIndex(t, idx2, text, patterns)
+	if err := idx2.UnmarshalBinary(buf[:len(buf)-1]); err == nil {
+		t.Error("Expected error")
+	}
+	buf[len(buf)/2] ^= 0x01
+	if err := idx2.UnmarshalBinary(buf); err != sbvector.ErrorChecksumMismatch {
+		t.Error("Expected", sbvector.ErrorChecksumMismatch, "got", err)
+	}
+}
+
+func TestIndexEmpty(t *testing.T) {
+	idx, err := New(nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := idx.Count([]byte("a")); n != 0 {
+		t.Error("Expected", 0, "got", n)
+	}
+	s, err := idx.Extract(0, 0)
+	if err != nil || len(s) != 0 {
+		t.Error("Expected empty string, got", s, err)
+	}
+}
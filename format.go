@@ -0,0 +1,166 @@
+package sbvector
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc64"
+)
+
+// Binary format of succinct bit vectors.
+//
+// The image consists of header, payload and trailer. Every field is little endian,
+// and every section of the payload is aligned to 8 bytes.
+//
+//	offset  size  field
+//	     0     8  magic "SBVECTOR"
+//	     8     2  format version
+//	    10     1  vector kind
+//	    11     1  index flags
+//	    12     4  reserved (0)
+//	    16     8  payload size in bytes (multiple of 8)
+//	    24     n  payload
+//	  24+n     8  CRC-64(ECMA) of header and payload
+//
+// Images written before the header was introduced start with their own size,
+// and are still readable by NewVectorFromBinary.
+const (
+	formatVersion uint16 = 1
+	headerSize    uint64 = 24
+	trailerSize   uint64 = 8
+	// maxImageSize is the upper bound of the size of images, which is larger than any addressable memory.
+	maxImageSize uint64 = 1 << 48
+)
+
+// Kinds of vectors stored in the binary format.
+const (
+	kindBitVector uint8 = 1
+	kindRRR       uint8 = 2
+	kindSparse    uint8 = 3
+	kindIntVector uint8 = 4
+	kindEliasFano uint8 = 5
+	kindRunLength uint8 = 6
+	kindHybrid    uint8 = 7
+	kindWavelet   uint8 = 8
+)
+
+// Index flags of BitVectorData stored in the binary format.
+const (
+	flagRankIndex    uint8 = 1 << 0
+	flagSelect1Index uint8 = 1 << 1
+	flagSelect0Index uint8 = 1 << 2
+)
+
+var (
+	formatMagic = []byte("SBVECTOR")
+	crcTable    = crc64.MakeTable(crc64.ECMA)
+)
+
+// formatHeader holds the fields of the header.
+type formatHeader struct {
+	kind        uint8
+	flags       uint8
+	payloadSize uint64
+}
+
+// hasMagic returns true if `data` starts with the magic number.
+func hasMagic(data []byte) bool {
+	return bytes.HasPrefix(data, formatMagic)
+}
+
+// binaryKind returns the kind of the vector in `data`.
+// Images without the header are BitVectorData.
+func binaryKind(data []byte) uint8 {
+	if hasMagic(data) && uint64(len(data)) >= headerSize {
+		return data[10]
+	}
+	return kindBitVector
+}
+
+// encodeHeader returns the header.
+func encodeHeader(header formatHeader) []byte {
+	buf := make([]byte, headerSize)
+	copy(buf, formatMagic)
+	binary.LittleEndian.PutUint16(buf[8:], formatVersion)
+	buf[10] = header.kind
+	buf[11] = header.flags
+	binary.LittleEndian.PutUint64(buf[16:], header.payloadSize)
+	return buf
+}
+
+// decodeHeader returns the header at the head of `buf`, `buf` must have headerSize bytes at least.
+func decodeHeader(buf []byte) (formatHeader, error) {
+	var header formatHeader
+	if !hasMagic(buf) {
+		return header, ErrorBadMagic
+	}
+	if binary.LittleEndian.Uint16(buf[8:]) != formatVersion {
+		return header, ErrorUnsupportedVersion
+	}
+	header.kind = buf[10]
+	header.flags = buf[11]
+	header.payloadSize = binary.LittleEndian.Uint64(buf[16:])
+	if header.payloadSize%sizeOfInt64 != 0 {
+		return header, ErrorInvalidFormat
+	}
+	return header, nil
+}
+
+// marshalContainer returns the image that holds `payload`.
+func marshalContainer(kind uint8, flags uint8, payload []byte) []byte {
+	buffer := bytes.NewBuffer(make([]byte, 0, headerSize+uint64(len(payload))+trailerSize))
+	buffer.Write(encodeHeader(formatHeader{kind, flags, uint64(len(payload))}))
+	buffer.Write(payload)
+	var checksum [8]byte
+	binary.LittleEndian.PutUint64(checksum[:], crc64.Checksum(buffer.Bytes(), crcTable))
+	buffer.Write(checksum[:])
+	return buffer.Bytes()
+}
+
+// unmarshalContainer checks the image of `kind`, and returns its header and payload.
+// If `verify` is false, the checksum is not verified.
+func unmarshalContainer(data []byte, kind uint8, verify bool) (formatHeader, []byte, error) {
+	if uint64(len(data)) < headerSize+trailerSize {
+		return formatHeader{}, nil, ErrorInvalidLength
+	}
+	header, err := decodeHeader(data)
+	if err != nil {
+		return header, nil, err
+	}
+	if header.kind != kind {
+		return header, nil, ErrorInvalidFormat
+	}
+	if header.payloadSize > uint64(len(data)) || headerSize+header.payloadSize+trailerSize != uint64(len(data)) {
+		return header, nil, ErrorInvalidLength
+	}
+	var body = data[:headerSize+header.payloadSize]
+	if verify && crc64.Checksum(body, crcTable) != binary.LittleEndian.Uint64(data[len(body):]) {
+		return header, nil, ErrorChecksumMismatch
+	}
+	return header, body[headerSize:], nil
+}
+
+// isLegacySize returns true if `x` can be the size at the head of images without the header.
+func isLegacySize(x uint64) bool {
+	return x < maxImageSize
+}
+
+// imageSize returns the size of the image at the head of `data`.
+func imageSize(data []byte) (uint64, error) {
+	if hasMagic(data) {
+		if uint64(len(data)) < headerSize {
+			return 0, ErrorInvalidLength
+		}
+		header, err := decodeHeader(data)
+		if err != nil {
+			return 0, err
+		}
+		if header.payloadSize > uint64(len(data)) {
+			return 0, ErrorInvalidLength
+		}
+		return headerSize + header.payloadSize + trailerSize, nil
+	}
+	if uint64(len(data)) < sizeOfInt64 {
+		return 0, ErrorInvalidLength
+	}
+	return binary.LittleEndian.Uint64(data), nil
+}
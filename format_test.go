@@ -0,0 +1,67 @@
+package sbvector
+
+import (
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	builder := NewVectorBuilder()
+	for _, v := range bitCases {
+		builder.Set(v.pos, v.bit)
+	}
+	vec, _ := builder.Build(true, false)
+	buffer, _ := vec.MarshalBinary()
+
+	if string(buffer[:8]) != "SBVECTOR" {
+		t.Error("Expected magic number")
+	}
+	if buffer[10] != kindBitVector || buffer[11] != flagRankIndex|flagSelect1Index {
+		t.Error("Expected kind", kindBitVector, "and flags", flagRankIndex|flagSelect1Index, "got", buffer[10], buffer[11])
+	}
+
+	legacy := legacyBinary(vec.(*BitVectorData))
+	vec2, err := NewVectorFromBinary(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareVectors(t, vec, vec2)
+
+	badBuf := make([]byte, len(buffer))
+
+	copy(badBuf, buffer)
+	badBuf[0] = 'X'
+	if _, err := NewVectorFromBinary(badBuf); err != ErrorBadMagic {
+		t.Error("Expected", ErrorBadMagic, "got", err)
+	}
+
+	copy(badBuf, buffer)
+	badBuf[8] = 2
+	if _, err := NewVectorFromBinary(badBuf); err != ErrorUnsupportedVersion {
+		t.Error("Expected", ErrorUnsupportedVersion, "got", err)
+	}
+
+	copy(badBuf, buffer)
+	badBuf[100] ^= 0x10
+	if _, err := NewVectorFromBinary(badBuf); err != ErrorChecksumMismatch {
+		t.Error("Expected", ErrorChecksumMismatch, "got", err)
+	}
+
+	if err := vec2.UnmarshalBinary(marshalContainer(kindBitVector, flagRankIndex, buffer[headerSize:len(buffer)-int(trailerSize)])); err != ErrorInvalidFormat {
+		t.Error("Expected", ErrorInvalidFormat, "got", err)
+	}
+
+	if _, err := NewVectorFromBinary(buffer[:len(buffer)-8]); err != ErrorInvalidLength {
+		t.Error("Expected", ErrorInvalidLength, "got", err)
+	}
+
+	rrrBuilder := NewVectorBuilder()
+	rrrBuilder.PushBack(true)
+	rrr, _ := rrrBuilder.BuildRRR()
+	rrrBuf, _ := rrr.MarshalBinary()
+	if err := new(SparseVectorData).UnmarshalBinary(rrrBuf); err != ErrorInvalidFormat {
+		t.Error("Expected", ErrorInvalidFormat, "got", err)
+	}
+	if err := new(RRRVectorData).UnmarshalBinary(legacy); err != ErrorBadMagic {
+		t.Error("Expected", ErrorBadMagic, "got", err)
+	}
+}
@@ -0,0 +1,507 @@
+package sbvector
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"github.com/hideo55/go-popcount"
+)
+
+const (
+	// hybridChunkSize is number of bits in a chunk of HybridVectorData.
+	hybridChunkSize   uint64 = 1 << 16
+	hybridChunkBlocks        = hybridChunkSize / sBlockSize
+)
+
+// Kinds of chunks of HybridVectorData.
+const (
+	hybridRaw   uint8 = 1
+	hybridArray uint8 = 2
+	hybridRun   uint8 = 3
+)
+
+// HybridVectorData holds information about hybrid bit vector.
+// Bits are partitioned into chunks of hybridChunkSize bits, and each chunk is stored as
+// raw blocks, sorted positions of 1s, or runs of 1s, whichever is the smallest.
+// Number of 1s before each chunk is held in the rank directory.
+type HybridVectorData struct {
+	chunks []hybridChunk
+	ranks  []uint64
+	size   uint64
+}
+
+// hybridChunk is a chunk of HybridVectorData.
+type hybridChunk struct {
+	kind      uint8
+	size      uint64
+	numOf1s   uint64
+	blocks    []uint64
+	ranks     []uint64
+	positions []uint16
+	runs      []uint16
+	runRanks  []uint64
+}
+
+// NewHybridVector returns new hybrid bit vector that has same bits as `src`.
+func NewHybridVector(src SuccinctBitVector) (*HybridVectorData, error) {
+	words, err := vectorWords(src)
+	if err != nil {
+		return nil, err
+	}
+	vec := new(HybridVectorData)
+	vec.size = src.Size()
+	for begin := uint64(0); begin < vec.size; begin += hybridChunkSize {
+		var size = vec.size - begin
+		if size > hybridChunkSize {
+			size = hybridChunkSize
+		}
+		blocks := make([]uint64, (size+sBlockSize-1)/sBlockSize)
+		for i := range blocks {
+			blocks[i] = wordAt(words, vec.size, begin/sBlockSize+uint64(i))
+		}
+		vec.chunks = append(vec.chunks, newHybridChunk(blocks, size))
+	}
+	vec.buildRanks()
+	return vec, nil
+}
+
+// newHybridChunk returns the smallest chunk that holds `size` bits in `blocks`.
+func newHybridChunk(blocks []uint64, size uint64) hybridChunk {
+	var numOf1s uint64
+	var numOfRuns uint64
+	var prev uint64
+	for _, x := range blocks {
+		numOf1s += popcount.Count(x)
+		// A run begins at each 1 whose previous bit is 0.
+		numOfRuns += popcount.Count(x &^ ((x << 1) | prev))
+		prev = x >> (sBlockSize - 1)
+	}
+	chunk := hybridChunk{size: size}
+	var rawSize = uint64(len(blocks)) * sizeOfInt64
+	switch {
+	case numOfRuns*4 < rawSize && numOfRuns*4 < numOf1s*2:
+		chunk.kind = hybridRun
+		for i := uint64(0); i < size; {
+			if (blocks[i/sBlockSize]>>(i%sBlockSize))&1 == 0 {
+				i++
+				continue
+			}
+			var start = i
+			for i < size && (blocks[i/sBlockSize]>>(i%sBlockSize))&1 == 1 {
+				i++
+			}
+			chunk.runs = append(chunk.runs, uint16(start), uint16(i-1))
+		}
+	case numOf1s*2 < rawSize:
+		chunk.kind = hybridArray
+		for i, x := range blocks {
+			for x != 0 {
+				chunk.positions = append(chunk.positions, uint16(uint64(i)*sBlockSize+uint64(countTrailingZeros(x))))
+				x &= x - 1
+			}
+		}
+	default:
+		chunk.kind = hybridRaw
+		chunk.blocks = blocks
+	}
+	chunk.buildRanks()
+	return chunk
+}
+
+// buildRanks builds the rank directory of the chunks.
+func (vec *HybridVectorData) buildRanks() {
+	vec.ranks = make([]uint64, len(vec.chunks)+1)
+	for c := range vec.chunks {
+		vec.ranks[c+1] = vec.ranks[c] + vec.chunks[c].numOf1s
+	}
+}
+
+// buildRanks builds the rank index of the chunk.
+// Raw chunks hold number of 1s before each large block, and run chunks hold number of 1s before each run.
+func (chunk *hybridChunk) buildRanks() {
+	chunk.numOf1s = 0
+	switch chunk.kind {
+	case hybridRaw:
+		chunk.ranks = make([]uint64, 0, uint64(len(chunk.blocks))/blockRate+1)
+		for i, x := range chunk.blocks {
+			if uint64(i)%blockRate == 0 {
+				chunk.ranks = append(chunk.ranks, chunk.numOf1s)
+			}
+			chunk.numOf1s += popcount.Count(x)
+		}
+	case hybridArray:
+		chunk.numOf1s = uint64(len(chunk.positions))
+	case hybridRun:
+		chunk.runRanks = make([]uint64, len(chunk.runs)/2+1)
+		for k := 0; k < len(chunk.runs)/2; k++ {
+			chunk.numOf1s += uint64(chunk.runs[2*k+1]) - uint64(chunk.runs[2*k]) + 1
+			chunk.runRanks[k+1] = chunk.numOf1s
+		}
+	}
+}
+
+// numOfRuns returns number of the runs of the run chunk.
+func (chunk *hybridChunk) numOfRuns() uint64 {
+	return uint64(len(chunk.runs)) / 2
+}
+
+// runsBefore returns number of the runs that start before position `i`.
+func (chunk *hybridChunk) runsBefore(i uint64) uint64 {
+	return uint64(sort.Search(int(chunk.numOfRuns()), func(k int) bool { return uint64(chunk.runs[2*k]) >= i }))
+}
+
+// positionsBefore returns number of the positions less than `i`.
+func (chunk *hybridChunk) positionsBefore(i uint64) uint64 {
+	return uint64(sort.Search(len(chunk.positions), func(k int) bool { return uint64(chunk.positions[k]) >= i }))
+}
+
+// rank1 returns number of the bits equal to `1` up to position `i` in the chunk.
+func (chunk *hybridChunk) rank1(i uint64) uint64 {
+	if i >= chunk.size {
+		return chunk.numOf1s
+	}
+	switch chunk.kind {
+	case hybridRaw:
+		var blockID = i / sBlockSize
+		var rank = chunk.ranks[blockID/blockRate]
+		for j := blockID / blockRate * blockRate; j < blockID; j++ {
+			rank += popcount.Count(chunk.blocks[j])
+		}
+		if r := i % sBlockSize; r != 0 {
+			rank += popcount.Count(mask(chunk.blocks[blockID], r))
+		}
+		return rank
+	case hybridArray:
+		return chunk.positionsBefore(i)
+	}
+	var k = chunk.runsBefore(i)
+	if k == 0 {
+		return 0
+	}
+	var last = uint64(chunk.runs[2*k-1])
+	if i > last {
+		return chunk.runRanks[k]
+	}
+	return chunk.runRanks[k-1] + (i - uint64(chunk.runs[2*k-2]))
+}
+
+// word returns the i-th 64 bits of the chunk.
+func (chunk *hybridChunk) word(i uint64) uint64 {
+	var begin = i * sBlockSize
+	var end = begin + sBlockSize
+	var x uint64
+	switch chunk.kind {
+	case hybridRaw:
+		return chunk.blocks[i]
+	case hybridArray:
+		for k := chunk.positionsBefore(begin); k < uint64(len(chunk.positions)) && uint64(chunk.positions[k]) < end; k++ {
+			x |= 1 << (uint64(chunk.positions[k]) - begin)
+		}
+	case hybridRun:
+		var k = chunk.runsBefore(begin)
+		if k > 0 {
+			k--
+		}
+		for ; k < chunk.numOfRuns() && uint64(chunk.runs[2*k]) < end; k++ {
+			var start = uint64(chunk.runs[2*k])
+			var last = uint64(chunk.runs[2*k+1]) + 1
+			if start < begin {
+				start = begin
+			}
+			if last > end {
+				last = end
+			}
+			if start < last {
+				x |= mask(^uint64(0), last-start) << (start - begin)
+			}
+		}
+	}
+	return x
+}
+
+// select1 returns the position of the x-th occurence of 1 in the chunk.
+func (chunk *hybridChunk) select1(x uint64) uint64 {
+	switch chunk.kind {
+	case hybridRaw:
+		var rankID = uint64(sort.Search(len(chunk.ranks), func(k int) bool { return chunk.ranks[k] > x })) - 1
+		x -= chunk.ranks[rankID]
+		for blockID := rankID * blockRate; ; blockID++ {
+			var count = popcount.Count(chunk.blocks[blockID])
+			if x < count {
+				return select64(chunk.blocks[blockID], x, blockID*sBlockSize)
+			}
+			x -= count
+		}
+	case hybridArray:
+		return uint64(chunk.positions[x])
+	}
+	var k = uint64(sort.Search(len(chunk.runRanks), func(k int) bool { return chunk.runRanks[k] > x })) - 1
+	return uint64(chunk.runs[2*k]) + (x - chunk.runRanks[k])
+}
+
+// select0 returns the position of the x-th occurence of 0 in the chunk.
+func (chunk *hybridChunk) select0(x uint64) uint64 {
+	switch chunk.kind {
+	case hybridRaw:
+		var rankID = uint64(sort.Search(len(chunk.ranks), func(k int) bool {
+			return uint64(k)*lBlockSize-chunk.ranks[k] > x
+		})) - 1
+		x -= rankID*lBlockSize - chunk.ranks[rankID]
+		for blockID := rankID * blockRate; ; blockID++ {
+			var count = sBlockSize - popcount.Count(chunk.blocks[blockID])
+			if x < count {
+				return select64(^chunk.blocks[blockID], x, blockID*sBlockSize)
+			}
+			x -= count
+		}
+	case hybridArray:
+		// The x-th 0 follows the positions preceded by x 0s or less.
+		var k = sort.Search(len(chunk.positions), func(k int) bool { return uint64(chunk.positions[k])-uint64(k) > x })
+		return x + uint64(k)
+	}
+	var k = sort.Search(int(chunk.numOfRuns()), func(k int) bool {
+		return uint64(chunk.runs[2*k])-chunk.runRanks[k] > x
+	})
+	return x + chunk.runRanks[k]
+}
+
+// Get returns value from bit vector by index.
+func (vec *HybridVectorData) Get(i uint64) (bool, error) {
+	if i >= vec.size {
+		return false, ErrorOutOfRange
+	}
+	var chunk = &vec.chunks[i/hybridChunkSize]
+	i %= hybridChunkSize
+	return (chunk.word(i/sBlockSize)>>(i%sBlockSize))&1 == 1, nil
+}
+
+// GetBits returns bits from bit vector.
+func (vec *HybridVectorData) GetBits(pos uint64, length uint64) (uint64, error) {
+	if (pos + length) > vec.size {
+		return NotFound, ErrorOutOfRange
+	}
+	if length == 0 {
+		return 0, nil
+	}
+	var blockIdx1 = pos / sBlockSize
+	var blockOffset1 = pos % sBlockSize
+	var x = vec.word(blockIdx1) >> blockOffset1
+	if (blockOffset1 + length) > sBlockSize {
+		x |= vec.word(blockIdx1+1) << (sBlockSize - blockOffset1)
+	}
+	return mask(x, length), nil
+}
+
+// word returns the i-th 64 bits of the bit vector.
+func (vec *HybridVectorData) word(i uint64) uint64 {
+	return vec.chunks[i/hybridChunkBlocks].word(i % hybridChunkBlocks)
+}
+
+// Rank1 returns number of the bits equal to `1` up to positin `i`
+func (vec *HybridVectorData) Rank1(i uint64) (uint64, error) {
+	if i > vec.size {
+		return NotFound, ErrorOutOfRange
+	}
+	var c = i / hybridChunkSize
+	if c == uint64(len(vec.chunks)) {
+		return vec.ranks[c], nil
+	}
+	return vec.ranks[c] + vec.chunks[c].rank1(i%hybridChunkSize), nil
+}
+
+// Rank0 returns number of the bits equal to `0` up to positin `i`
+func (vec *HybridVectorData) Rank0(i uint64) (uint64, error) {
+	rank, err := vec.Rank1(i)
+	if err != nil {
+		return rank, err
+	}
+	return i - rank, nil
+}
+
+// Rank returns number of the bits equal to `b` up to positin `i`
+func (vec *HybridVectorData) Rank(i uint64, b bool) (uint64, error) {
+	if b {
+		return vec.Rank1(i)
+	}
+	return vec.Rank0(i)
+}
+
+// Select1 returns the position of the x-th occurence of 1
+func (vec *HybridVectorData) Select1(x uint64) (uint64, error) {
+	if vec.NumOfBits(true) <= x {
+		return NotFound, ErrorOutOfRange
+	}
+	var c = uint64(sort.Search(len(vec.chunks), func(c int) bool { return vec.ranks[c+1] > x }))
+	return c*hybridChunkSize + vec.chunks[c].select1(x-vec.ranks[c]), nil
+}
+
+// Select0 returns the position of the x-th occurence of 0
+func (vec *HybridVectorData) Select0(x uint64) (uint64, error) {
+	if vec.NumOfBits(false) <= x {
+		return NotFound, ErrorOutOfRange
+	}
+	var c = uint64(sort.Search(len(vec.chunks), func(c int) bool {
+		return uint64(c+1)*hybridChunkSize-vec.ranks[c+1] > x
+	}))
+	var zeros = c*hybridChunkSize - vec.ranks[c]
+	return c*hybridChunkSize + vec.chunks[c].select0(x-zeros), nil
+}
+
+// Select returns the position of the x-th occurrence of `b`
+func (vec *HybridVectorData) Select(x uint64, b bool) (uint64, error) {
+	if b {
+		return vec.Select1(x)
+	}
+	return vec.Select0(x)
+}
+
+// NextOne returns the position of the first 1 at or after position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *HybridVectorData) NextOne(i uint64) (uint64, error) {
+	return nextOf(vec, i, true)
+}
+
+// NextZero returns the position of the first 0 at or after position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *HybridVectorData) NextZero(i uint64) (uint64, error) {
+	return nextOf(vec, i, false)
+}
+
+// PrevOne returns the position of the last 1 at or before position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *HybridVectorData) PrevOne(i uint64) (uint64, error) {
+	return prevOf(vec, i, true)
+}
+
+// PrevZero returns the position of the last 0 at or before position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *HybridVectorData) PrevZero(i uint64) (uint64, error) {
+	return prevOf(vec, i, false)
+}
+
+// Size returns size of bit vector
+func (vec *HybridVectorData) Size() uint64 {
+	return vec.size
+}
+
+// NumOfBits returns number of bits that matches with argument in the bit vector.
+func (vec *HybridVectorData) NumOfBits(b bool) uint64 {
+	var numOf1s = vec.ranks[len(vec.chunks)]
+	if b {
+		return numOf1s
+	}
+	return vec.size - numOf1s
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// Each chunk is stored as its kind, number of its elements and the elements padded to 8 bytes.
+func (vec *HybridVectorData) MarshalBinary() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	binary.Write(buffer, binary.LittleEndian, &vec.size)
+	for _, chunk := range vec.chunks {
+		var kind = uint64(chunk.kind)
+		binary.Write(buffer, binary.LittleEndian, &kind)
+		switch chunk.kind {
+		case hybridRaw:
+			var n = uint64(len(chunk.blocks))
+			binary.Write(buffer, binary.LittleEndian, &n)
+			binary.Write(buffer, binary.LittleEndian, chunk.blocks)
+		case hybridArray:
+			writeUint16s(buffer, chunk.positions)
+		case hybridRun:
+			writeUint16s(buffer, chunk.runs)
+		}
+	}
+	return marshalContainer(kindHybrid, 0, buffer.Bytes()), nil
+}
+
+// writeUint16s writes number of `s` and `s` padded to 8 bytes.
+func writeUint16s(buffer *bytes.Buffer, s []uint16) {
+	var n = uint64(len(s))
+	binary.Write(buffer, binary.LittleEndian, &n)
+	binary.Write(buffer, binary.LittleEndian, s)
+	buffer.Write(make([]byte, (sizeOfInt64-(n*2)%sizeOfInt64)%sizeOfInt64))
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (vec *HybridVectorData) UnmarshalBinary(data []byte) error {
+	_, payload, err := unmarshalContainer(data, kindHybrid, true)
+	if err != nil {
+		return err
+	}
+	if uint64(len(payload)) < sizeOfInt64 {
+		return ErrorInvalidFormat
+	}
+	vec.size = binary.LittleEndian.Uint64(payload)
+	vec.chunks = nil
+	var buf = payload[sizeOfInt64:]
+	for begin := uint64(0); begin < vec.size; begin += hybridChunkSize {
+		chunk := hybridChunk{size: vec.size - begin}
+		if chunk.size > hybridChunkSize {
+			chunk.size = hybridChunkSize
+		}
+		if uint64(len(buf)) < sizeOfInt64*2 {
+			return ErrorInvalidFormat
+		}
+		var kind = binary.LittleEndian.Uint64(buf)
+		var n = binary.LittleEndian.Uint64(buf[sizeOfInt64:])
+		buf = buf[sizeOfInt64*2:]
+		var dataSize = n * 2
+		if kind == uint64(hybridRaw) {
+			dataSize = n * sizeOfInt64
+		}
+		dataSize = (dataSize + sizeOfInt64 - 1) / sizeOfInt64 * sizeOfInt64
+		if n > hybridChunkSize*2 || dataSize > uint64(len(buf)) {
+			return ErrorInvalidFormat
+		}
+		switch uint8(kind) {
+		case hybridRaw:
+			if n != (chunk.size+sBlockSize-1)/sBlockSize {
+				return ErrorInvalidFormat
+			}
+			chunk.blocks = decodeUint64s(buf[:dataSize], false)
+			if r := chunk.size % sBlockSize; r != 0 && chunk.blocks[n-1] != mask(chunk.blocks[n-1], r) {
+				return ErrorInvalidFormat
+			}
+		case hybridArray:
+			chunk.positions = decodeUint16s(buf[:n*2])
+			for k, pos := range chunk.positions {
+				if uint64(pos) >= chunk.size || (k > 0 && pos <= chunk.positions[k-1]) {
+					return ErrorInvalidFormat
+				}
+			}
+		case hybridRun:
+			if n%2 != 0 {
+				return ErrorInvalidFormat
+			}
+			chunk.runs = decodeUint16s(buf[:n*2])
+			for k := uint64(0); k < n; k += 2 {
+				var start, last = uint64(chunk.runs[k]), uint64(chunk.runs[k+1])
+				if start > last || last >= chunk.size || (k > 0 && start <= uint64(chunk.runs[k-1])) {
+					return ErrorInvalidFormat
+				}
+			}
+		default:
+			return ErrorInvalidFormat
+		}
+		chunk.kind = uint8(kind)
+		chunk.buildRanks()
+		vec.chunks = append(vec.chunks, chunk)
+		buf = buf[dataSize:]
+	}
+	if len(buf) != 0 {
+		return ErrorInvalidFormat
+	}
+	vec.buildRanks()
+	return nil
+}
+
+// decodeUint16s returns uint16 values in `data`.
+func decodeUint16s(data []byte) []uint16 {
+	s := make([]uint16, len(data)/2)
+	for i := range s {
+		s[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	return s
+}
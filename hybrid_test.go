@@ -0,0 +1,95 @@
+package sbvector
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestHybridVector(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	builder := NewVectorBuilder()
+	// Dense chunk.
+	for i := uint64(0); i < hybridChunkSize; i++ {
+		builder.PushBack(r.Intn(2) == 1)
+	}
+	// Sparse chunk.
+	for i := uint64(0); i < hybridChunkSize; i++ {
+		builder.PushBack(r.Intn(1000) == 0)
+	}
+	// Chunk of long runs.
+	var b bool
+	for i := uint64(0); i < hybridChunkSize; {
+		for n := r.Intn(2000) + 1; n > 0 && i < hybridChunkSize; n-- {
+			builder.PushBack(b)
+			i++
+		}
+		b = !b
+	}
+	// Partial chunk.
+	for i := 0; i < 1000; i++ {
+		builder.PushBack(r.Intn(3) == 0)
+	}
+	expected, _ := builder.Build(true, true)
+	vec, err := NewHybridVector(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for c, kind := range []uint8{hybridRaw, hybridArray, hybridRun, hybridRaw} {
+		if vec.chunks[c].kind != kind {
+			t.Error("Expected", kind, "got", vec.chunks[c].kind)
+		}
+	}
+	compareVectors(t, expected, vec)
+	for _, pos := range []uint64{0, hybridChunkSize - 30, 2*hybridChunkSize - 1, 3*hybridChunkSize - 64} {
+		x1, _ := expected.GetBits(pos, 64)
+		x2, err := vec.GetBits(pos, 64)
+		if err != nil || x1 != x2 {
+			t.Error("Expected", x1, "got", x2)
+		}
+	}
+
+	buf, err := vec.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vec2, err := NewVectorFromBinary(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := vec2.(*HybridVectorData); !ok {
+		t.Fatal("Expected *HybridVectorData")
+	}
+	compareVectors(t, expected, vec2)
+	if err := vec2.UnmarshalBinary(buf[:len(buf)-1]); err == nil {
+		t.Error("Expected error")
+	}
+}
+
+func TestHybridVectorSmall(t *testing.T) {
+	for _, s := range []string{"", "0", "1", "0011100111", "0000000000000000000000000000000000000000000000000000000000000000001"} {
+		expected, _ := NewVectorFromString(s)
+		vec, err := NewHybridVector(expected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		compareVectors(t, expected, vec)
+		buf, _ := vec.MarshalBinary()
+		vec2 := new(HybridVectorData)
+		if err := vec2.UnmarshalBinary(buf); err != nil {
+			t.Fatal(err)
+		}
+		compareVectors(t, expected, vec2)
+	}
+
+	vec, _ := NewVectorFromString("0011100111")
+	hv, _ := NewHybridVector(vec)
+	if _, err := hv.Get(10); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+	if _, err := hv.Select1(6); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+	if _, err := hv.Select0(4); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+}
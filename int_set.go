@@ -0,0 +1,193 @@
+package sbvector
+
+import (
+	"sort"
+
+	"github.com/hideo55/go-popcount"
+)
+
+// IntSet is set of uint64 integers that supports rank/select over its members.
+// Members are held by BitVectorData or SparseVectorData, whichever is smaller.
+type IntSet struct {
+	vec SuccinctBitVector
+}
+
+// NewIntSet returns new set that holds `members`. `members` may be unsorted and may contain duplicates.
+// `members` must not contain NotFound.
+func NewIntSet(members []uint64) (*IntSet, error) {
+	var sorted = make([]uint64, len(members))
+	copy(sorted, members)
+	sort.Sort(uint64Slice(sorted))
+	var n = 0
+	for i, x := range sorted {
+		if x == NotFound {
+			return nil, ErrorOutOfRange
+		}
+		if i == 0 || x != sorted[n-1] {
+			sorted[n] = x
+			n++
+		}
+	}
+	return newIntSet(sorted[:n]), nil
+}
+
+// newIntSet returns new set that holds `members`. `members` must be sorted and unique.
+func newIntSet(members []uint64) *IntSet {
+	var universe uint64
+	if len(members) > 0 {
+		universe = members[len(members)-1] + 1
+	}
+	if preferSparse(uint64(len(members)), universe) {
+		return &IntSet{newSparseVectorFromPositions(members, universe)}
+	}
+	vec := new(BitVectorData)
+	for _, x := range members {
+		vec.set(x, true)
+	}
+	vec.build(true, false)
+	return &IntSet{vec}
+}
+
+// preferSparse returns true if SparseVectorData is smaller than BitVectorData for `n` members less than `universe`.
+// BitVectorData takes `universe` bits, and SparseVectorData takes about `2 + lowWidth` bits per member.
+func preferSparse(n uint64, universe uint64) bool {
+	var sparseBits = 2 * n
+	if n > 0 && universe > n {
+		sparseBits += n * (bitWidth(universe/n) - 1)
+	}
+	return sparseBits < universe
+}
+
+// Len returns number of the members.
+func (set *IntSet) Len() uint64 {
+	return set.vec.NumOfBits(true)
+}
+
+// Contains returns true if `x` is a member of the set.
+func (set *IntSet) Contains(x uint64) bool {
+	if x >= set.vec.Size() {
+		return false
+	}
+	b, _ := set.vec.Get(x)
+	return b
+}
+
+// Rank returns number of the members less than `x`.
+func (set *IntSet) Rank(x uint64) uint64 {
+	if x >= set.vec.Size() {
+		return set.Len()
+	}
+	rank, _ := set.vec.Rank1(x)
+	return rank
+}
+
+// Select returns the k-th smallest member.
+func (set *IntSet) Select(k uint64) (uint64, error) {
+	return set.vec.Select1(k)
+}
+
+// Min returns the smallest member.
+func (set *IntSet) Min() (uint64, error) {
+	return set.vec.Select1(0)
+}
+
+// Max returns the largest member.
+func (set *IntSet) Max() (uint64, error) {
+	if set.Len() == 0 {
+		return NotFound, ErrorOutOfRange
+	}
+	return set.vec.Select1(set.Len() - 1)
+}
+
+// CountRange returns number of the members `x` such that lower <= x < upper.
+func (set *IntSet) CountRange(lower uint64, upper uint64) uint64 {
+	if lower >= upper {
+		return 0
+	}
+	return set.Rank(upper) - set.Rank(lower)
+}
+
+// Intersect returns new set that holds the members of both `set` and `other`.
+// Dense sets are intersected word by word. Otherwise both sets are walked in ascending order,
+// skipping to the next member not less than the current member of the other set.
+func (set *IntSet) Intersect(other *IntSet) *IntSet {
+	x, ok1 := set.vec.(*BitVectorData)
+	y, ok2 := other.vec.(*BitVectorData)
+	if ok1 && ok2 {
+		return intersectBlocks(x, y)
+	}
+	var members []uint64
+	for pos := set.next(0); pos != NotFound; {
+		var next = other.next(pos)
+		if next == pos {
+			members = append(members, pos)
+			next++
+		}
+		pos = set.next(next)
+	}
+	return newIntSet(members)
+}
+
+// next returns the smallest member not less than `x`, or NotFound if there is no such member.
+func (set *IntSet) next(x uint64) uint64 {
+	if x >= set.vec.Size() {
+		return NotFound
+	}
+	if vec, ok := set.vec.(*SparseVectorData); ok {
+		pos, _ := vec.ef.NextGEQ(x)
+		return pos
+	}
+	pos, _ := set.vec.NextOne(x)
+	return pos
+}
+
+// intersectBlocks returns new set that holds the bits set in both `x` and `y`.
+func intersectBlocks(x *BitVectorData, y *BitVectorData) *IntSet {
+	var numOfBlocks = len(x.blocks)
+	if len(y.blocks) < numOfBlocks {
+		numOfBlocks = len(y.blocks)
+	}
+	blocks := make([]uint64, numOfBlocks)
+	var n uint64
+	for i := range blocks {
+		blocks[i] = x.blocks[i] & y.blocks[i]
+		n += popcount.Count(blocks[i])
+	}
+	for numOfBlocks > 0 && blocks[numOfBlocks-1] == 0 {
+		numOfBlocks--
+	}
+	blocks = blocks[:numOfBlocks]
+	var universe uint64
+	if numOfBlocks > 0 {
+		universe = uint64(numOfBlocks-1)*sBlockSize + bitWidth(blocks[numOfBlocks-1])
+	}
+	if preferSparse(n, universe) {
+		members := make([]uint64, 0, n)
+		for i, block := range blocks {
+			for ; block != 0; block &= block - 1 {
+				members = append(members, uint64(i)*sBlockSize+uint64(countTrailingZeros(block)))
+			}
+		}
+		return &IntSet{newSparseVectorFromPositions(members, universe)}
+	}
+	vec := new(BitVectorData)
+	vec.blocks = blocks
+	vec.size = universe
+	vec.build(true, false)
+	return &IntSet{vec}
+}
+
+// uint64Slice attaches the methods of sort.Interface to []uint64.
+type uint64Slice []uint64
+
+func (s uint64Slice) Len() int {
+	return len(s)
+}
+
+func (s uint64Slice) Less(i, j int) bool {
+	return s[i] < s[j]
+}
+
+func (s uint64Slice) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
@@ -0,0 +1,146 @@
+package sbvector
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestIntSet(t *testing.T) {
+	set, err := NewIntSet(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.Len() != 0 || set.Contains(0) || set.Rank(10) != 0 {
+		t.Error("Expected empty set")
+	}
+	if _, err := set.Min(); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+	if _, err := set.Max(); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+	if _, err := NewIntSet([]uint64{NotFound}); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	var sets []*IntSet
+	var members []map[uint64]bool
+	for _, universe := range []int64{2000, 1 << 40} {
+		var values []uint64
+		var m = make(map[uint64]bool)
+		for i := 0; i < 1000; i++ {
+			var x = uint64(r.Int63n(universe))
+			values = append(values, x, x)
+			m[x] = true
+		}
+		set, err := NewIntSet(values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := set.vec.(*SparseVectorData); ok != (universe > 2000) {
+			t.Error("Unexpected representation", universe)
+		}
+		checkIntSet(t, set, m, r, universe)
+		sets = append(sets, set)
+		members = append(members, m)
+	}
+
+	var m = make(map[uint64]bool)
+	var values []uint64
+	for x := range members[0] {
+		if r.Intn(2) == 0 {
+			values = append(values, x)
+			m[x] = true
+		}
+	}
+	set, _ = NewIntSet(values)
+	checkIntSet(t, sets[0].Intersect(set), m, r, 2000)
+	checkIntSet(t, set.Intersect(sets[0]), m, r, 2000)
+	m = make(map[uint64]bool)
+	for x := range members[0] {
+		if members[1][x] {
+			m[x] = true
+		}
+	}
+	checkIntSet(t, sets[1].Intersect(sets[0]), m, r, 2000)
+
+	// Both sparse.
+	m = make(map[uint64]bool)
+	values = nil
+	for x := range members[1] {
+		if r.Intn(2) == 0 {
+			values = append(values, x)
+			m[x] = true
+		}
+	}
+	values = append(values, 1<<41)
+	set, _ = NewIntSet(values)
+	checkIntSet(t, sets[1].Intersect(set), m, r, 1<<40)
+	checkIntSet(t, set.Intersect(sets[1]), m, r, 1<<40)
+
+	// Both dense, and the intersection is sparse.
+	var odds, evens = []uint64{1500}, []uint64(nil)
+	for x := uint64(0); x < 3000; x += 2 {
+		odds = append(odds, x+1)
+		evens = append(evens, x)
+	}
+	set, _ = NewIntSet(odds)
+	set2, _ := NewIntSet(evens)
+	if _, ok := set.vec.(*BitVectorData); !ok {
+		t.Error("Unexpected representation")
+	}
+	set = set.Intersect(set2)
+	if _, ok := set.vec.(*SparseVectorData); !ok {
+		t.Error("Unexpected representation")
+	}
+	checkIntSet(t, set, map[uint64]bool{1500: true}, r, 3000)
+}
+
+// checkIntSet checks that `set` holds `members`.
+func checkIntSet(t *testing.T, set *IntSet, members map[uint64]bool, r *rand.Rand, universe int64) {
+	if set.Len() != uint64(len(members)) {
+		t.Fatal("Expected", len(members), "got", set.Len())
+	}
+	var sorted []uint64
+	for x := range members {
+		sorted = append(sorted, x)
+	}
+	sort.Sort(uint64Slice(sorted))
+	for k, x := range sorted {
+		if v, err := set.Select(uint64(k)); err != nil || v != x {
+			t.Fatal("Select", k, "Expected", x, "got", v)
+		}
+		if rank := set.Rank(x); rank != uint64(k) {
+			t.Fatal("Rank", x, "Expected", k, "got", rank)
+		}
+		if !set.Contains(x) {
+			t.Fatal("Contains", x)
+		}
+	}
+	if len(sorted) > 0 {
+		if x, err := set.Min(); err != nil || x != sorted[0] {
+			t.Error("Expected", sorted[0], "got", x)
+		}
+		if x, err := set.Max(); err != nil || x != sorted[len(sorted)-1] {
+			t.Error("Expected", sorted[len(sorted)-1], "got", x)
+		}
+	}
+	for k := 0; k < 200; k++ {
+		var lower = uint64(r.Int63n(universe))
+		var upper = uint64(r.Int63n(universe))
+		var count uint64
+		for x := range members {
+			if lower <= x && x < upper {
+				count++
+			}
+		}
+		if c := set.CountRange(lower, upper); c != count {
+			t.Fatal("CountRange", lower, upper, "Expected", count, "got", c)
+		}
+		if set.Contains(lower) != members[lower] {
+			t.Fatal("Contains", lower)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package sbvector
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// ErrorInvalidWidth indicates that width of integer is invalid.
+var ErrorInvalidWidth = errors.New("Invalid width of integer")
+
+// IntVector is array of fixed width integers.
+// Values are packed into the blocks of BitVectorData without gaps.
+type IntVector struct {
+	vec    BitVectorData
+	width  uint64
+	length uint64
+}
+
+// NewIntVector returns new empty integer array whose values are `width` bits.
+// `width` must be between 1 and 64.
+func NewIntVector(width uint64) (*IntVector, error) {
+	if width == 0 || width > sBlockSize {
+		return nil, ErrorInvalidWidth
+	}
+	return &IntVector{width: width}, nil
+}
+
+// Width returns number of bits of each value.
+func (iv *IntVector) Width() uint64 {
+	return iv.width
+}
+
+// Len returns number of values in the array.
+func (iv *IntVector) Len() uint64 {
+	return iv.length
+}
+
+// Get returns the i-th value.
+func (iv *IntVector) Get(i uint64) (uint64, error) {
+	if i >= iv.length {
+		return NotFound, ErrorOutOfRange
+	}
+	return iv.vec.GetBits(i*iv.width, iv.width)
+}
+
+// Set sets the i-th value to `v`.
+func (iv *IntVector) Set(i uint64, v uint64) error {
+	if i >= iv.length || bitWidth(v) > iv.width {
+		return ErrorOutOfRange
+	}
+	iv.vec.setBits(i*iv.width, v, iv.width)
+	return nil
+}
+
+// Append adds `v` at the end of the array.
+func (iv *IntVector) Append(v uint64) error {
+	if bitWidth(v) > iv.width {
+		return ErrorOutOfRange
+	}
+	iv.vec.pushBackBits(v, iv.width)
+	iv.length++
+	return nil
+}
+
+// Decode stores values from the i-th into `dst`, and returns number of the stored values.
+func (iv *IntVector) Decode(i uint64, dst []uint64) (uint64, error) {
+	if i > iv.length {
+		return 0, ErrorOutOfRange
+	}
+	var n = iv.length - i
+	if uint64(len(dst)) < n {
+		n = uint64(len(dst))
+	}
+	var pos = i * iv.width
+	for k := uint64(0); k < n; k++ {
+		dst[k], _ = iv.vec.GetBits(pos, iv.width)
+		pos += iv.width
+	}
+	return n, nil
+}
+
+// Values returns all values in the array.
+func (iv *IntVector) Values() []uint64 {
+	values := make([]uint64, iv.length)
+	iv.Decode(0, values)
+	return values
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (iv *IntVector) MarshalBinary() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	binary.Write(buffer, binary.LittleEndian, &iv.width)
+	binary.Write(buffer, binary.LittleEndian, &iv.length)
+	binary.Write(buffer, binary.LittleEndian, iv.vec.blocks)
+	return marshalContainer(kindIntVector, 0, buffer.Bytes()), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (iv *IntVector) UnmarshalBinary(data []byte) error {
+	_, payload, err := unmarshalContainer(data, kindIntVector, true)
+	if err != nil {
+		return err
+	}
+	if uint64(len(payload)) < sizeOfInt64*2 {
+		return ErrorInvalidFormat
+	}
+	var width = binary.LittleEndian.Uint64(payload)
+	var length = binary.LittleEndian.Uint64(payload[sizeOfInt64:])
+	if width == 0 || width > sBlockSize {
+		return ErrorInvalidFormat
+	}
+	var blockNum = (uint64(len(payload)) - sizeOfInt64*2) / sizeOfInt64
+	if length > blockNum*sBlockSize/width || blockNum != (length*width+sBlockSize-1)/sBlockSize {
+		return ErrorInvalidFormat
+	}
+	iv.vec = BitVectorData{}
+	iv.vec.blocks = decodeUint64s(payload[sizeOfInt64*2:], false)
+	iv.vec.size = length * width
+	iv.width = width
+	iv.length = length
+	return nil
+}
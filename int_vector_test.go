@@ -0,0 +1,84 @@
+package sbvector
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestIntVector(t *testing.T) {
+	if _, err := NewIntVector(0); err != ErrorInvalidWidth {
+		t.Error("Expected", ErrorInvalidWidth, "got", err)
+	}
+	if _, err := NewIntVector(65); err != ErrorInvalidWidth {
+		t.Error("Expected", ErrorInvalidWidth, "got", err)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for _, width := range []uint64{1, 3, 7, 13, 32, 63, 64} {
+		iv, err := NewIntVector(width)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var values = make([]uint64, 1000)
+		for i := range values {
+			values[i] = mask(r.Uint64(), width)
+			if err := iv.Append(values[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for i := 0; i < 500; i++ {
+			var k = r.Intn(len(values))
+			values[k] = mask(r.Uint64(), width)
+			if err := iv.Set(uint64(k), values[k]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if iv.Len() != uint64(len(values)) || iv.Width() != width {
+			t.Error("Expected", len(values), width, "got", iv.Len(), iv.Width())
+		}
+		for i, v := range values {
+			if x, err := iv.Get(uint64(i)); err != nil || x != v {
+				t.Fatal("Get", i, "Expected", v, "got", x)
+			}
+		}
+		var dst = make([]uint64, 100)
+		if n, err := iv.Decode(950, dst); err != nil || n != 50 {
+			t.Error("Expected", 50, "got", n)
+		}
+		for i := 0; i < 50; i++ {
+			if dst[i] != values[950+i] {
+				t.Fatal("Decode", i, "Expected", values[950+i], "got", dst[i])
+			}
+		}
+
+		buf, err := iv.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		iv2 := new(IntVector)
+		if err := iv2.UnmarshalBinary(buf); err != nil {
+			t.Fatal(err)
+		}
+		var decoded = iv2.Values()
+		for i, v := range values {
+			if decoded[i] != v {
+				t.Fatal("Values", i, "Expected", v, "got", decoded[i])
+			}
+		}
+		if err := iv2.UnmarshalBinary(buf[:len(buf)-1]); err == nil {
+			t.Error("Expected error")
+		}
+
+		if width < 64 {
+			if err := iv.Append(uint64(1) << width); err != ErrorOutOfRange {
+				t.Error("Expected", ErrorOutOfRange, "got", err)
+			}
+			if err := iv.Set(0, uint64(1)<<width); err != ErrorOutOfRange {
+				t.Error("Expected", ErrorOutOfRange, "got", err)
+			}
+		}
+		if _, err := iv.Get(iv.Len()); err != ErrorOutOfRange {
+			t.Error("Expected", ErrorOutOfRange, "got", err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+//go:build go1.23
+
+package sbvector
+
+import "iter"
+
+// Ones returns an iterator over the positions of 1s in the bit vector.
+func (vec *BitVectorData) Ones() iter.Seq[uint64] {
+	return vec.positionsFrom(0, true)
+}
+
+// Zeros returns an iterator over the positions of 0s in the bit vector.
+func (vec *BitVectorData) Zeros() iter.Seq[uint64] {
+	return vec.positionsFrom(0, false)
+}
+
+// OnesFrom returns an iterator over the positions of 1s at or after position `i`.
+func (vec *BitVectorData) OnesFrom(i uint64) iter.Seq[uint64] {
+	return vec.positionsFrom(i, true)
+}
+
+// ZerosFrom returns an iterator over the positions of 0s at or after position `i`.
+func (vec *BitVectorData) ZerosFrom(i uint64) iter.Seq[uint64] {
+	return vec.positionsFrom(i, false)
+}
+
+// positionsFrom walks blocks and yields the positions of `b` at or after position `i`.
+func (vec *BitVectorData) positionsFrom(i uint64, b bool) iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		for blockID := i / sBlockSize; blockID < uint64(len(vec.blocks)); blockID++ {
+			var x = vec.blocks[blockID]
+			if !b {
+				x = ^x
+			}
+			if blockID == i/sBlockSize {
+				x &= ^((uint64(1) << (i % sBlockSize)) - 1)
+			}
+			for x != 0 {
+				var pos = blockID*sBlockSize + uint64(countTrailingZeros(x))
+				if pos >= vec.size || !yield(pos) {
+					return
+				}
+				x &= x - 1
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the values of the sequence.
+func (ef *EliasFano) Values() iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		it := ef.Iterator(0)
+		for v, ok := it.Next(); ok; v, ok = it.Next() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
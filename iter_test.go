@@ -0,0 +1,78 @@
+//go:build go1.23
+
+package sbvector
+
+import "testing"
+
+func TestIterator(t *testing.T) {
+	builder := NewVectorBuilder()
+
+	for _, v := range bitCases {
+		builder.Set(v.pos, v.bit)
+	}
+
+	vec, _ := builder.Build(false, false)
+	bv := vec.(*BitVectorData)
+
+	var x uint64
+	for pos := range bv.Ones() {
+		expected, _ := vec.Select1(x)
+		if pos != expected {
+			t.Error("Expected", expected, "got", pos)
+		}
+		x++
+	}
+	if x != vec.NumOfBits(true) {
+		t.Error("Expected", vec.NumOfBits(true), "got", x)
+	}
+
+	x = 0
+	for pos := range bv.Zeros() {
+		expected, _ := vec.Select0(x)
+		if pos != expected {
+			t.Error("Expected", expected, "got", pos)
+		}
+		x++
+	}
+	if x != vec.NumOfBits(false) {
+		t.Error("Expected", vec.NumOfBits(false), "got", x)
+	}
+
+	x = 4
+	for pos := range bv.OnesFrom(65) {
+		expected, _ := vec.Select1(x)
+		if pos != expected {
+			t.Error("Expected", expected, "got", pos)
+		}
+		x++
+	}
+	if x != vec.NumOfBits(true) {
+		t.Error("Expected", vec.NumOfBits(true), "got", x)
+	}
+
+	for pos := range bv.ZerosFrom(1024) {
+		if pos != 1024 {
+			t.Error("Expected", 1024, "got", pos)
+		}
+		break
+	}
+
+	for range bv.OnesFrom(vec.Size()) {
+		t.Error("Expected no positions")
+	}
+}
+
+func TestEliasFanoValues(t *testing.T) {
+	var values = []uint64{0, 3, 3, 17, 1000, 1001, 65536}
+	ef, _ := NewEliasFano(values)
+	var i int
+	for v := range ef.Values() {
+		if v != values[i] {
+			t.Error("Expected", values[i], "got", v)
+		}
+		i++
+	}
+	if i != len(values) {
+		t.Error("Expected", len(values), "got", i)
+	}
+}
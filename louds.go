@@ -0,0 +1,147 @@
+package sbvector
+
+// LOUDS is ordinal tree represented by level-order unary degree sequence.
+// Each node is numbered in BFS order from 0 (the root), and the node of id `x` corresponds to the x-th `1` of the bit vector.
+// The bit vector begins with `10` of the super root, followed by `1`s for each child and `0` of each node in BFS order.
+type LOUDS struct {
+	vec *BitVectorData
+}
+
+// BuildLOUDS returns new LOUDS tree built by breadth first traversal.
+// `numOfChildren` is called for each node in BFS order with the id of the node, and must return number of the children of the node.
+func BuildLOUDS(numOfChildren func(id uint64) uint64) (*LOUDS, error) {
+	builder := NewVectorBuilder()
+	builder.PushBack(true)
+	builder.PushBack(false)
+	var numOfNodes = uint64(1)
+	for id := uint64(0); id < numOfNodes; id++ {
+		var degree = numOfChildren(id)
+		for i := uint64(0); i < degree; i++ {
+			builder.PushBack(true)
+		}
+		builder.PushBack(false)
+		numOfNodes += degree
+	}
+	vec, err := builder.Build(true, true)
+	if err != nil {
+		return nil, err
+	}
+	return &LOUDS{vec.(*BitVectorData)}, nil
+}
+
+// NumOfNodes returns number of the nodes in the tree.
+func (tree *LOUDS) NumOfNodes() uint64 {
+	return tree.vec.NumOfBits(true)
+}
+
+// BitVector returns the bit vector that holds the tree.
+func (tree *LOUDS) BitVector() *BitVectorData {
+	return tree.vec
+}
+
+// Position returns the position of the bit that corresponds to the node `x`.
+func (tree *LOUDS) Position(x uint64) (uint64, error) {
+	return tree.vec.Select1(x)
+}
+
+// NodeID returns the id of the node that corresponds to the bit at position `pos`.
+func (tree *LOUDS) NodeID(pos uint64) (uint64, error) {
+	if pos >= tree.vec.Size() {
+		return NotFound, ErrorOutOfRange
+	}
+	if b, _ := tree.vec.Get(pos); !b {
+		return NotFound, ErrorOutOfRange
+	}
+	return tree.vec.Rank1(pos)
+}
+
+// Parent returns the id of the parent of the node `x`.
+// If `x` is the root, returns NotFound.
+func (tree *LOUDS) Parent(x uint64) (uint64, error) {
+	pos, err := tree.vec.Select1(x)
+	if err != nil {
+		return NotFound, err
+	}
+	rank, _ := tree.vec.Rank0(pos)
+	if rank == 0 {
+		return NotFound, nil
+	}
+	return rank - 1, nil
+}
+
+// FirstChild returns the id of the first child of the node `x`.
+// If `x` is a leaf, returns NotFound.
+func (tree *LOUDS) FirstChild(x uint64) (uint64, error) {
+	if x >= tree.NumOfNodes() {
+		return NotFound, ErrorOutOfRange
+	}
+	pos, _ := tree.vec.Select0(x)
+	pos++
+	if b, _ := tree.vec.Get(pos); !b {
+		return NotFound, nil
+	}
+	return tree.vec.Rank1(pos)
+}
+
+// NextSibling returns the id of the next sibling of the node `x`.
+// If `x` is the last child of its parent, returns NotFound.
+func (tree *LOUDS) NextSibling(x uint64) (uint64, error) {
+	pos, err := tree.vec.Select1(x)
+	if err != nil {
+		return NotFound, err
+	}
+	if b, _ := tree.vec.Get(pos + 1); !b {
+		return NotFound, nil
+	}
+	return x + 1, nil
+}
+
+// Degree returns number of the children of the node `x`.
+func (tree *LOUDS) Degree(x uint64) (uint64, error) {
+	if x >= tree.NumOfNodes() {
+		return NotFound, ErrorOutOfRange
+	}
+	begin, _ := tree.vec.Select0(x)
+	end, _ := tree.vec.Select0(x + 1)
+	return end - begin - 1, nil
+}
+
+// ChildCount returns number of the children of the node `x`. It is same as Degree.
+func (tree *LOUDS) ChildCount(x uint64) (uint64, error) {
+	return tree.Degree(x)
+}
+
+// Child returns the id of the k-th child of the node `x`.
+func (tree *LOUDS) Child(x uint64, k uint64) (uint64, error) {
+	degree, err := tree.Degree(x)
+	if err != nil {
+		return NotFound, err
+	}
+	if k >= degree {
+		return NotFound, ErrorOutOfRange
+	}
+	first, _ := tree.FirstChild(x)
+	return first + k, nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The tree is stored as the image of its bit vector.
+func (tree *LOUDS) MarshalBinary() ([]byte, error) {
+	return tree.vec.MarshalBinary()
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (tree *LOUDS) UnmarshalBinary(data []byte) error {
+	vec := new(BitVectorData)
+	if err := vec.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	if vec.Size() < 2 || vec.NumOfBits(false) != vec.NumOfBits(true)+1 {
+		return ErrorInvalidFormat
+	}
+	if x, _ := vec.GetBits(0, 2); x != 1 {
+		return ErrorInvalidFormat
+	}
+	tree.vec = vec
+	return nil
+}
@@ -0,0 +1,125 @@
+package sbvector
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestLOUDS(t *testing.T) {
+	// Tree of 10 nodes in BFS order.
+	//        0
+	//      / | \
+	//     1  2  3
+	//    / \    |
+	//   4   5   6
+	//      /|\
+	//     7 8 9
+	var degrees = []uint64{3, 2, 0, 1, 0, 3, 0, 0, 0, 0}
+	var parents = []uint64{NotFound, 0, 0, 0, 1, 1, 3, 5, 5, 5}
+	tree, err := BuildLOUDS(func(id uint64) uint64 { return degrees[id] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLOUDS(t, tree, degrees, parents)
+
+	buf, err := tree.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree2 := new(LOUDS)
+	if err := tree2.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	checkLOUDS(t, tree2, degrees, parents)
+
+	if _, err := tree.Parent(10); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+	if _, err := tree.FirstChild(10); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+	if _, err := tree.NodeID(1); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+	if _, err := tree.Child(0, 3); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+
+	builder := NewVectorBuilder()
+	builder.PushBack(false)
+	builder.PushBack(true)
+	builder.PushBack(false)
+	vec, _ := builder.Build(false, false)
+	buf, _ = vec.MarshalBinary()
+	if err := tree2.UnmarshalBinary(buf); err != ErrorInvalidFormat {
+		t.Error("Expected", ErrorInvalidFormat, "got", err)
+	}
+}
+
+func TestLOUDSRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var degrees []uint64
+	var parents = []uint64{NotFound}
+	for id := uint64(0); id < uint64(len(parents)); id++ {
+		var degree uint64
+		if len(parents) < 5000 {
+			degree = uint64(r.Intn(4))
+		}
+		for i := uint64(0); i < degree; i++ {
+			parents = append(parents, id)
+		}
+		degrees = append(degrees, degree)
+	}
+	tree, err := BuildLOUDS(func(id uint64) uint64 { return degrees[id] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLOUDS(t, tree, degrees, parents)
+}
+
+// checkLOUDS checks that `tree` holds the tree of `degrees` and `parents`.
+func checkLOUDS(t *testing.T, tree *LOUDS, degrees []uint64, parents []uint64) {
+	if n := tree.NumOfNodes(); n != uint64(len(degrees)) {
+		t.Fatal("Expected", len(degrees), "got", n)
+	}
+	var firstChild = make([]uint64, len(degrees))
+	for i := range firstChild {
+		firstChild[i] = NotFound
+	}
+	for x := len(parents) - 1; x > 0; x-- {
+		firstChild[parents[x]] = uint64(x)
+	}
+	for x := uint64(0); x < uint64(len(degrees)); x++ {
+		if p, err := tree.Parent(x); err != nil || p != parents[x] {
+			t.Fatal("Parent", x, "Expected", parents[x], "got", p)
+		}
+		if d, err := tree.Degree(x); err != nil || d != degrees[x] {
+			t.Fatal("Degree", x, "Expected", degrees[x], "got", d)
+		}
+		if d, err := tree.ChildCount(x); err != nil || d != degrees[x] {
+			t.Fatal("ChildCount", x, "Expected", degrees[x], "got", d)
+		}
+		if c, err := tree.FirstChild(x); err != nil || c != firstChild[x] {
+			t.Fatal("FirstChild", x, "Expected", firstChild[x], "got", c)
+		}
+		var sibling = NotFound
+		if x > 0 && x+1 < uint64(len(parents)) && parents[x+1] == parents[x] {
+			sibling = x + 1
+		}
+		if s, err := tree.NextSibling(x); err != nil || s != sibling {
+			t.Fatal("NextSibling", x, "Expected", sibling, "got", s)
+		}
+		if degrees[x] > 0 {
+			if c, err := tree.Child(x, degrees[x]-1); err != nil || parents[c] != x {
+				t.Fatal("Child", x, "got", c)
+			}
+		}
+		pos, err := tree.Position(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id, err := tree.NodeID(pos); err != nil || id != x {
+			t.Fatal("NodeID", pos, "Expected", x, "got", id)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package sbvector
+
+import "os"
+
+// MappedVector holds succinct bit vector that is loaded from memory mapped file.
+// Blocks and indexes of the vector share memory with the file, so that loading takes constant time
+// and the read-only pages are shared between processes. The vector must not be used after Close.
+type MappedVector struct {
+	SuccinctBitVector
+	data []byte
+}
+
+// NewVectorFromBinaryNoCopy returns new succinct bit vector that initialize by binary data without copying it.
+// Blocks and indexes of BitVectorData alias `data` as long as each section is aligned to 8 bytes,
+// otherwise the section is copied. Every section is aligned if `data` is aligned to 8 bytes and
+// has the header, but images without the header have unaligned sections.
+// The checksum is not verified, so that loading takes constant time.
+// `data` must not be modified while the vector is used. Vectors other than BitVectorData are always copied.
+func NewVectorFromBinaryNoCopy(data []byte) (SuccinctBitVector, error) {
+	if binaryKind(data) != kindBitVector {
+		return NewVectorFromBinary(data)
+	}
+	vec := new(BitVectorData)
+	err := vec.unmarshal(data, true)
+	return vec, err
+}
+
+// OpenMappedVector maps the file that holds binary data of succinct bit vector, and returns the vector.
+// Only images written with the header are mapped without copying, because sections of images without the header are not aligned.
+func OpenMappedVector(path string) (*MappedVector, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if uint64(info.Size()) < minimumSize {
+		return nil, ErrorInvalidLength
+	}
+	data, err := mapFile(f, int(info.Size()))
+	if err != nil {
+		return nil, err
+	}
+	vec, err := NewVectorFromBinaryNoCopy(data)
+	if err != nil {
+		unmapFile(data)
+		return nil, err
+	}
+	return &MappedVector{vec, data}, nil
+}
+
+// Close unmaps the file.
+func (vec *MappedVector) Close() error {
+	if vec.data == nil {
+		return nil
+	}
+	err := unmapFile(vec.data)
+	vec.data = nil
+	vec.SuccinctBitVector = newVectorFromBlocks(nil, 0)
+	return err
+}
@@ -0,0 +1,101 @@
+//go:build go1.17
+
+package sbvector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"unsafe"
+)
+
+func TestNoCopy(t *testing.T) {
+	builder := NewVectorBuilder()
+	for _, v := range bitCases {
+		builder.Set(v.pos, v.bit)
+	}
+	vec, _ := builder.Build(true, true)
+	image, _ := vec.MarshalBinary()
+	var blockNum = uint64(len(vec.(*BitVectorData).blocks))
+
+	backing := make([]uint64, len(image)/8+2)
+	memory := unsafe.Slice((*byte)(unsafe.Pointer(&backing[0])), len(backing)*8)
+	for _, shift := range []int{0, 4} {
+		data := memory[shift : shift+len(image)]
+		copy(data, image)
+		vec2, err := NewVectorFromBinaryNoCopy(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		compareVectors(t, vec, vec2)
+
+		bv := vec2.(*BitVectorData)
+		var aliased = &bv.blocks[0] == (*uint64)(unsafe.Pointer(&data[72])) &&
+			&bv.ranks[0] == (*rankIndex)(unsafe.Pointer(&data[72+blockNum*8]))
+		if aliased != (hostLittleEndian && shift == 0) {
+			t.Error("Expected aliased", hostLittleEndian && shift == 0, "got", aliased)
+		}
+	}
+
+	legacy := legacyBinary(vec.(*BitVectorData))
+	vec2, err := NewVectorFromBinaryNoCopy(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareVectors(t, vec, vec2)
+
+	if _, err := NewVectorFromBinaryNoCopy(image[:len(image)-1]); err != ErrorInvalidLength {
+		t.Error("Expected", ErrorInvalidLength, "got", err)
+	}
+}
+
+func TestMappedVector(t *testing.T) {
+	builder := NewVectorBuilder()
+	for i := uint64(0); i < 100000; i++ {
+		builder.PushBack(i%7 == 0)
+	}
+	vec, _ := builder.Build(true, true)
+
+	path := filepath.Join(t.TempDir(), "vector.bin")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vec.(*BitVectorData).WriteTo(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	mapped, err := OpenMappedVector(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareVectors(t, vec, mapped)
+	builder = NewVectorBuilderWithInit(mapped.SuccinctBitVector)
+	builder.Set(1, true)
+	if b, _ := builder.Get(1); !b {
+		t.Error("Expected", true, "got", b)
+	}
+	mapped2, err := OpenMappedVector(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareVectors(t, vec, mapped2)
+	mapped2.Close()
+	if err := mapped.Close(); err != nil {
+		t.Error(err)
+	}
+	if mapped.Size() != 0 {
+		t.Error("Expected", 0, "got", mapped.Size())
+	}
+	if rank, err := mapped.Rank1(0); err != nil || rank != 0 {
+		t.Error("Expected", 0, "got", rank, err)
+	}
+	if _, err := mapped.Select1(0); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+
+	if _, err := OpenMappedVector(filepath.Join(t.TempDir(), "missing.bin")); err == nil {
+		t.Error("Expected error")
+	}
+}
@@ -47,6 +47,27 @@ func (index *rankIndex) abs() uint64 {
 	return index.absVal
 }
 
+// relOf returns number of non-zero bits from the head of the large block to the head of the k-th small block.
+func (index *rankIndex) relOf(k uint64) uint64 {
+	switch k {
+	case 1:
+		return index.rel1()
+	case 2:
+		return index.rel2()
+	case 3:
+		return index.rel3()
+	case 4:
+		return index.rel4()
+	case 5:
+		return index.rel5()
+	case 6:
+		return index.rel6()
+	case 7:
+		return index.rel7()
+	}
+	return 0
+}
+
 func (index *rankIndex) rel1() uint64 {
 	return (index.rel & mask7F)
 }
@@ -79,6 +100,26 @@ func (index *rankIndex) setAbs(val uint64) {
 	index.absVal = val
 }
 
+// setRelOf sets number of non-zero bits from the head of the large block to the head of the k-th small block.
+func (index *rankIndex) setRelOf(k uint64, val uint64) {
+	switch k {
+	case 1:
+		index.setRel1(val)
+	case 2:
+		index.setRel2(val)
+	case 3:
+		index.setRel3(val)
+	case 4:
+		index.setRel4(val)
+	case 5:
+		index.setRel5(val)
+	case 6:
+		index.setRel6(val)
+	case 7:
+		index.setRel7(val)
+	}
+}
+
 func (index *rankIndex) setRel1(val uint64) {
 	index.rel = ((index.rel & ^mask7F) | (val & mask7F))
 }
@@ -0,0 +1,254 @@
+package sbvector
+
+// rmqBlockSize is number of parentheses in a block of the sparse table of RMQ.
+const rmqBlockSize = lBlockSize
+
+// rmqByteMinPos holds the largest `j` such that P_j is bpByteMin of the byte, where P_j is prefix excess of the first j bits.
+var rmqByteMinPos [256]uint8
+
+func init() {
+	for b := 0; b < 256; b++ {
+		var ex, m int8 = 0, 8
+		for j := uint8(1); j <= 8; j++ {
+			if (b>>(j-1))&1 == 1 {
+				ex++
+			} else {
+				ex--
+			}
+			if ex <= m {
+				m = ex
+				rmqByteMinPos[b] = j
+			}
+		}
+	}
+}
+
+// RMQ answers range minimum queries over a static array of integers without holding the array.
+// The Cartesian tree of the array, where the parent of each value is the nearest preceding value not greater than it,
+// is held by balanced parentheses of 2n+2 bits. The i-th value is the node of the (i+1)-th open parenthesis,
+// and the minimum of a range is the node at the last position of the minimum excess between the nodes.
+// The position is found in O(1) time by two-level directory over minimum excess of blocks, and by the excess tables
+// of bytes in at most two blocks at the ends of the range. The sparse table over superblocks of O(log^2 n) bits
+// holds absolute blocks, and the sparse tables inside superblocks hold blocks relative to each block,
+// so that the directory takes less than 0.4 bits per value however large the array is.
+type RMQ struct {
+	vec       *BitVectorData
+	minPos    *IntVector
+	inner     []*IntVector
+	outer     []*IntVector
+	superSize uint64
+	length    uint64
+}
+
+// NewRMQ returns new RMQ over `values`.
+func NewRMQ(values []int64) (*RMQ, error) {
+	builder := NewVectorBuilder()
+	builder.PushBack(true)
+	var stack []int64
+	for _, v := range values {
+		for len(stack) > 0 && stack[len(stack)-1] > v {
+			stack = stack[:len(stack)-1]
+			builder.PushBack(false)
+		}
+		stack = append(stack, v)
+		builder.PushBack(true)
+	}
+	for i := 0; i <= len(stack); i++ {
+		builder.PushBack(false)
+	}
+	vec, err := builder.Build(true, false)
+	if err != nil {
+		return nil, err
+	}
+	rmq := &RMQ{vec: vec.(*BitVectorData), length: uint64(len(values))}
+	if err := rmq.buildTable(); err != nil {
+		return nil, err
+	}
+	return rmq, nil
+}
+
+// buildTable builds the directory. The k-th level of the inner tables holds the block of the last minimum excess
+// in 2^(k+1) blocks from each block, clipped at the end of its superblock, relative to the block.
+// The k-th level of the outer table holds the block of the last minimum excess in 2^(k+1) superblocks from each superblock.
+func (rmq *RMQ) buildTable() error {
+	var size = rmq.vec.Size()
+	var numOfBlocks = (size + rmqBlockSize - 1) / rmqBlockSize
+	var width = bitWidth(numOfBlocks)
+	// The outer table takes at most width^2 bits per superblock, which is kept under 1/8 bits per parenthesis.
+	rmq.superSize = 1
+	for rmq.superSize*rmqBlockSize < 8*width*width {
+		rmq.superSize <<= 1
+	}
+	var err error
+	rmq.minPos, err = NewIntVector(bitWidth(rmqBlockSize - 1))
+	if err != nil {
+		return err
+	}
+	mins := make([]int64, numOfBlocks)
+	prev := make([]uint64, numOfBlocks)
+	for w := uint64(0); w < numOfBlocks; w++ {
+		var end = (w+1)*rmqBlockSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		pos, m := rmq.scan(w*rmqBlockSize, end, NotFound, 0)
+		rmq.minPos.Append(pos - w*rmqBlockSize)
+		mins[w] = m
+		prev[w] = w
+	}
+	for span := uint64(2); span <= rmq.superSize; span <<= 1 {
+		level, err := NewIntVector(bitWidth(rmq.superSize - 1))
+		if err != nil {
+			return err
+		}
+		for w := uint64(0); w < numOfBlocks; w++ {
+			var right = w + span/2
+			if right/rmq.superSize == w/rmq.superSize && right < numOfBlocks && mins[prev[right]] <= mins[prev[w]] {
+				prev[w] = prev[right]
+			}
+			level.Append(prev[w] - w)
+		}
+		rmq.inner = append(rmq.inner, level)
+	}
+	var numOfSupers = (numOfBlocks + rmq.superSize - 1) / rmq.superSize
+	for u := uint64(0); u < numOfSupers; u++ {
+		prev[u] = prev[u*rmq.superSize]
+	}
+	for span := uint64(2); span <= numOfSupers; span <<= 1 {
+		level, err := NewIntVector(width)
+		if err != nil {
+			return err
+		}
+		for u := uint64(0); u+span <= numOfSupers; u++ {
+			if right := prev[u+span/2]; mins[right] <= mins[prev[u]] {
+				prev[u] = right
+			}
+			level.Append(prev[u])
+		}
+		rmq.outer = append(rmq.outer, level)
+	}
+	return nil
+}
+
+// Len returns number of the values.
+func (rmq *RMQ) Len() uint64 {
+	return rmq.length
+}
+
+// Query returns the position of the minimum value in range [l, r).
+// If there are several minimum values, returns the leftmost one.
+func (rmq *RMQ) Query(l uint64, r uint64) (uint64, error) {
+	if l >= r || r > rmq.length {
+		return NotFound, ErrorOutOfRange
+	}
+	x, _ := rmq.vec.Select1(l + 1)
+	y, _ := rmq.vec.Select1(r)
+	// Values between `l` and `r-1` are in the subtrees of the siblings of the minimum that precede it,
+	// so the last position of the minimum excess is the open parenthesis of the minimum.
+	rank, _ := rmq.vec.Rank1(rmq.minExcessPos(x, y))
+	return rank - 1, nil
+}
+
+// minExcessPos returns the last position of the minimum excess in range [a, b].
+func (rmq *RMQ) minExcessPos(a uint64, b uint64) uint64 {
+	var ba, bb = a / rmqBlockSize, b / rmqBlockSize
+	if ba == bb {
+		pos, _ := rmq.scan(a, b, NotFound, 0)
+		return pos
+	}
+	pos, m := rmq.scan(a, (ba+1)*rmqBlockSize-1, NotFound, 0)
+	if ba+1 < bb {
+		var q = rmq.blockMinPos(rmq.blockRangeMin(ba+1, bb-1))
+		if ex := rmq.excess(q); ex <= m {
+			pos, m = q, ex
+		}
+	}
+	pos, _ = rmq.scan(bb*rmqBlockSize, b, pos, m)
+	return pos
+}
+
+// blockRangeMin returns the block of the last minimum excess in blocks [l, r].
+func (rmq *RMQ) blockRangeMin(l uint64, r uint64) uint64 {
+	var ul, ur = l / rmq.superSize, r / rmq.superSize
+	if ul == ur {
+		return rmq.innerRangeMin(l, r)
+	}
+	var w = rmq.innerRangeMin(l, (ul+1)*rmq.superSize-1)
+	if ul+1 < ur {
+		w = rmq.lastMin(w, rmq.outerRangeMin(ul+1, ur-1))
+	}
+	return rmq.lastMin(w, rmq.innerRangeMin(ur*rmq.superSize, r))
+}
+
+// innerRangeMin returns the block of the last minimum excess in blocks [l, r] in a superblock.
+func (rmq *RMQ) innerRangeMin(l uint64, r uint64) uint64 {
+	if l == r {
+		return l
+	}
+	var k = bitWidth(r-l+1) - 1
+	var m = r + 1 - (1 << k)
+	left, _ := rmq.inner[k-1].Get(l)
+	right, _ := rmq.inner[k-1].Get(m)
+	return rmq.lastMin(l+left, m+right)
+}
+
+// outerRangeMin returns the block of the last minimum excess in superblocks [l, r].
+func (rmq *RMQ) outerRangeMin(l uint64, r uint64) uint64 {
+	if l == r {
+		return rmq.innerRangeMin(l*rmq.superSize, (l+1)*rmq.superSize-1)
+	}
+	var k = bitWidth(r-l+1) - 1
+	left, _ := rmq.outer[k-1].Get(l)
+	right, _ := rmq.outer[k-1].Get(r + 1 - (1 << k))
+	return rmq.lastMin(left, right)
+}
+
+// lastMin returns the block of the smaller minimum excess of the blocks `left` and `right`, or `right` if they are equal.
+func (rmq *RMQ) lastMin(left uint64, right uint64) uint64 {
+	if rmq.excess(rmq.blockMinPos(right)) <= rmq.excess(rmq.blockMinPos(left)) {
+		return right
+	}
+	return left
+}
+
+// blockMinPos returns the last position of the minimum excess in the block `w`.
+func (rmq *RMQ) blockMinPos(w uint64) uint64 {
+	offset, _ := rmq.minPos.Get(w)
+	return w*rmqBlockSize + offset
+}
+
+// excess returns number of open parentheses minus number of close parentheses in the first `p` parentheses.
+func (rmq *RMQ) excess(p uint64) int64 {
+	rank, _ := rmq.vec.Rank1(p)
+	return int64(2*rank) - int64(p)
+}
+
+// scan returns the last position of the minimum excess in range [a, b] and the minimum.
+// If `pos` is not NotFound, it is the position of the minimum `m` before `a`, which is returned if it is less than the others.
+func (rmq *RMQ) scan(a uint64, b uint64, pos uint64, m int64) (uint64, int64) {
+	var ex = rmq.excess(a)
+	if pos == NotFound || ex <= m {
+		pos, m = a, ex
+	}
+	for q := a; q < b; {
+		if q%8 == 0 && q+8 <= b {
+			var x = uint8(rmq.vec.blocks[q/sBlockSize] >> (q % sBlockSize))
+			if ex+int64(bpByteMin[x]) <= m {
+				pos, m = q+uint64(rmqByteMinPos[x]), ex+int64(bpByteMin[x])
+			}
+			ex += int64(bpByteExcess[x])
+			q += 8
+			continue
+		}
+		if (rmq.vec.blocks[q/sBlockSize]>>(q%sBlockSize))&1 == 1 {
+			ex++
+		} else {
+			ex--
+		}
+		q++
+		if ex <= m {
+			pos, m = q, ex
+		}
+	}
+	return pos, m
+}
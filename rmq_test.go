@@ -0,0 +1,123 @@
+package sbvector
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func naiveRMQ(values []int64, l int, r int) uint64 {
+	var m = l
+	for i := l + 1; i < r; i++ {
+		if values[i] < values[m] {
+			m = i
+		}
+	}
+	return uint64(m)
+}
+
+func TestRMQ(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	values := make([]int64, 100)
+	for i := range values {
+		values[i] = r.Int63n(20) - 10
+	}
+	rmq, err := NewRMQ(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rmq.Len() != 100 {
+		t.Error("Expected", 100, "got", rmq.Len())
+	}
+	for l := 0; l < len(values); l++ {
+		for j := l + 1; j <= len(values); j++ {
+			expected := naiveRMQ(values, l, j)
+			if m, err := rmq.Query(uint64(l), uint64(j)); err != nil || m != expected {
+				t.Fatal("Query", l, j, "Expected", expected, "got", m)
+			}
+		}
+	}
+
+	values = make([]int64, 20000)
+	for i := range values {
+		values[i] = r.Int63n(1000)
+	}
+	rmq, err = NewRMQ(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := 0; k < 2000; k++ {
+		l := r.Intn(len(values))
+		j := l + 1 + r.Intn(len(values)-l)
+		expected := naiveRMQ(values, l, j)
+		if m, err := rmq.Query(uint64(l), uint64(j)); err != nil || m != expected {
+			t.Fatal("Query", l, j, "Expected", expected, "got", m)
+		}
+	}
+
+	for i := range values {
+		values[i] = int64(i % 3000)
+	}
+	for _, vs := range [][]int64{values, values[:4000]} {
+		rmq, _ = NewRMQ(vs)
+		for k := 0; k < 2000; k++ {
+			l := r.Intn(len(vs))
+			j := l + 1 + r.Intn(len(vs)-l)
+			expected := naiveRMQ(vs, l, j)
+			if m, err := rmq.Query(uint64(l), uint64(j)); err != nil || m != expected {
+				t.Fatal("Query", l, j, "Expected", expected, "got", m)
+			}
+		}
+	}
+
+	if _, err := rmq.Query(5, 5); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+	if _, err := rmq.Query(0, 20001); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+	rmq, _ = NewRMQ(nil)
+	if _, err := rmq.Query(0, 1); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+}
+
+// rmqDirectoryBits returns number of bits of the directory of `rmq`.
+func rmqDirectoryBits(rmq *RMQ) uint64 {
+	var bits = rmq.minPos.Len() * rmq.minPos.Width()
+	for _, levels := range [][]*IntVector{rmq.inner, rmq.outer} {
+		for _, level := range levels {
+			bits += level.Len() * level.Width()
+		}
+	}
+	return bits
+}
+
+func TestRMQSpace(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{1 << 16, 1 << 18, 1 << 20} {
+		values := make([]int64, n)
+		for i := range values {
+			values[i] = r.Int63n(1 << 20)
+		}
+		rmq, err := NewRMQ(values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The outer table takes at most 1/4 bits per value, and minPos and the inner tables take at most 0.1 bits per value.
+		var bitsPerValue = float64(rmqDirectoryBits(rmq)) / float64(n)
+		if bitsPerValue > 0.4 {
+			t.Error("Directory takes", bitsPerValue, "bits per value for", n, "values")
+		}
+		for k := 0; k < 300; k++ {
+			l := r.Intn(len(values))
+			j := l + 1 + r.Intn(len(values)-l)
+			if j-l > 50000 {
+				j = l + 1 + r.Intn(50000)
+			}
+			expected := naiveRMQ(values, l, j)
+			if m, err := rmq.Query(uint64(l), uint64(j)); err != nil || m != expected {
+				t.Fatal("Query", l, j, "Expected", expected, "got", m)
+			}
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package sbvector
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/hideo55/go-popcount"
+)
+
+// RRRVectorData holds information about bit vector compressed by RRR(Raman, Raman and Rao) encoding.
+//
+// The bit vector is divided into blocks of 63 bits. Each block is stored as pair of
+// its class(number of 1s in the block) and its offset(index of the block among all
+// blocks of the same class). The offset is stored in variable length,
+// so that skewed bit vector takes less space than BitVectorData.
+type RRRVectorData struct {
+	classes       BitVectorData
+	offsets       BitVectorData
+	rankSamples   []uint64
+	offsetSamples []uint64
+	numOf1s       uint64
+	size          uint64
+}
+
+const (
+	rrrBlockSize  uint64 = 63
+	rrrClassWidth uint64 = 6
+	rrrSampleRate uint64 = 32
+)
+
+var (
+	binomialTable  [rrrBlockSize + 1][rrrBlockSize + 1]uint64
+	rrrOffsetWidth [rrrBlockSize + 1]uint64
+)
+
+func init() {
+	for n := uint64(0); n <= rrrBlockSize; n++ {
+		binomialTable[n][0] = 1
+		for k := uint64(1); k <= n; k++ {
+			binomialTable[n][k] = binomialTable[n-1][k-1] + binomialTable[n-1][k]
+		}
+	}
+	for k := uint64(0); k <= rrrBlockSize; k++ {
+		rrrOffsetWidth[k] = bitWidth(binomialTable[rrrBlockSize][k] - 1)
+	}
+}
+
+// newRRRVector returns new RRR encoded bit vector that has same bits as `src`.
+func newRRRVector(src *BitVectorData) *RRRVectorData {
+	vec := new(RRRVectorData)
+	vec.size = src.size
+	for pos := uint64(0); pos < src.size; pos += rrrBlockSize {
+		var length = rrrBlockSize
+		if pos+length > src.size {
+			length = src.size - pos
+		}
+		bits, _ := src.GetBits(pos, length)
+		var class = popcount.Count(bits)
+		vec.classes.pushBackBits(class, rrrClassWidth)
+		if width := rrrOffsetWidth[class]; width > 0 {
+			vec.offsets.pushBackBits(rrrEncode(bits, class), width)
+		}
+		vec.numOf1s += class
+	}
+	vec.buildSamples()
+	return vec
+}
+
+func (vec *RRRVectorData) numOfBlocks() uint64 {
+	return (vec.size + rrrBlockSize - 1) / rrrBlockSize
+}
+
+func (vec *RRRVectorData) class(blockID uint64) uint64 {
+	class, _ := vec.classes.GetBits(blockID*rrrClassWidth, rrrClassWidth)
+	return class
+}
+
+// block returns bits of the block, `offset` is position of the block in offsets.
+func (vec *RRRVectorData) block(blockID uint64, offset uint64) uint64 {
+	var class = vec.class(blockID)
+	var width = rrrOffsetWidth[class]
+	if width == 0 {
+		return rrrDecode(0, class)
+	}
+	code, _ := vec.offsets.GetBits(offset, width)
+	return rrrDecode(code, class)
+}
+
+func (vec *RRRVectorData) buildSamples() {
+	var blockNum = vec.numOfBlocks()
+	var rank uint64
+	var offset uint64
+	vec.rankSamples = make([]uint64, 0, blockNum/rrrSampleRate+2)
+	vec.offsetSamples = make([]uint64, 0, blockNum/rrrSampleRate+2)
+	for i := uint64(0); i < blockNum; i++ {
+		if i%rrrSampleRate == 0 {
+			vec.rankSamples = append(vec.rankSamples, rank)
+			vec.offsetSamples = append(vec.offsetSamples, offset)
+		}
+		var class = vec.class(i)
+		rank += class
+		offset += rrrOffsetWidth[class]
+	}
+	vec.rankSamples = append(vec.rankSamples, rank)
+	vec.offsetSamples = append(vec.offsetSamples, offset)
+}
+
+// blockRank returns number of 1s before the block, and position of the block in offsets.
+func (vec *RRRVectorData) blockRank(blockID uint64) (uint64, uint64) {
+	var sampleID = blockID / rrrSampleRate
+	var rank = vec.rankSamples[sampleID]
+	var offset = vec.offsetSamples[sampleID]
+	for i := sampleID * rrrSampleRate; i < blockID; i++ {
+		var class = vec.class(i)
+		rank += class
+		offset += rrrOffsetWidth[class]
+	}
+	return rank, offset
+}
+
+// sampledBlock returns ID of the first block covered by the sample.
+func (vec *RRRVectorData) sampledBlock(sampleID uint64) uint64 {
+	var blockID = sampleID * rrrSampleRate
+	if blockNum := vec.numOfBlocks(); blockID > blockNum {
+		return blockNum
+	}
+	return blockID
+}
+
+// Get returns value from bit vector by index.
+func (vec *RRRVectorData) Get(i uint64) (bool, error) {
+	if i >= vec.size {
+		return false, ErrorOutOfRange
+	}
+	var blockID = i / rrrBlockSize
+	_, offset := vec.blockRank(blockID)
+	return (vec.block(blockID, offset) & (1 << (i % rrrBlockSize))) != 0, nil
+}
+
+// GetBits returns bits from bit vector.
+func (vec *RRRVectorData) GetBits(pos uint64, length uint64) (uint64, error) {
+	if (pos + length) > vec.size {
+		return NotFound, ErrorOutOfRange
+	}
+	var blockID = pos / rrrBlockSize
+	var r = pos % rrrBlockSize
+	_, offset := vec.blockRank(blockID)
+	var result uint64
+	var shift uint64
+	for shift < length {
+		result |= (vec.block(blockID, offset) >> r) << shift
+		shift += rrrBlockSize - r
+		offset += rrrOffsetWidth[vec.class(blockID)]
+		blockID++
+		r = 0
+	}
+	return mask(result, length), nil
+}
+
+// Rank1 returns number of the bits equal to `1` up to positin `i`
+func (vec *RRRVectorData) Rank1(i uint64) (uint64, error) {
+	if i > vec.size {
+		return NotFound, ErrorOutOfRange
+	}
+	var blockID = i / rrrBlockSize
+	rank, offset := vec.blockRank(blockID)
+	if r := i % rrrBlockSize; r > 0 {
+		rank += popcount.Count(mask(vec.block(blockID, offset), r))
+	}
+	return rank, nil
+}
+
+// Rank0 returns number of the bits equal to `0` up to positin `i`
+func (vec *RRRVectorData) Rank0(i uint64) (uint64, error) {
+	rank, err := vec.Rank1(i)
+	if err != nil {
+		return rank, err
+	}
+	return i - rank, nil
+}
+
+// Rank returns number of the bits equal to `b` up to position `i`
+func (vec *RRRVectorData) Rank(i uint64, b bool) (uint64, error) {
+	if b {
+		return vec.Rank1(i)
+	}
+	return vec.Rank0(i)
+}
+
+// Select1 returns the position of the x-th occurence of 1
+func (vec *RRRVectorData) Select1(x uint64) (uint64, error) {
+	if vec.numOf1s <= x {
+		return NotFound, ErrorOutOfRange
+	}
+	var begin uint64
+	var end = uint64(len(vec.rankSamples)) - 1
+	for (begin + 1) < end {
+		var pivot = (begin + end) / 2
+		if x < vec.rankSamples[pivot] {
+			end = pivot
+		} else {
+			begin = pivot
+		}
+	}
+	var blockID = begin * rrrSampleRate
+	var rank = vec.rankSamples[begin]
+	var offset = vec.offsetSamples[begin]
+	for {
+		var class = vec.class(blockID)
+		if x < rank+class {
+			break
+		}
+		rank += class
+		offset += rrrOffsetWidth[class]
+		blockID++
+	}
+	return select64(vec.block(blockID, offset), x-rank, blockID*rrrBlockSize), nil
+}
+
+// Select0 returns the position of the x-th occurence of 0
+func (vec *RRRVectorData) Select0(x uint64) (uint64, error) {
+	if vec.NumOfBits(false) <= x {
+		return NotFound, ErrorOutOfRange
+	}
+	var begin uint64
+	var end = uint64(len(vec.rankSamples)) - 1
+	for (begin + 1) < end {
+		var pivot = (begin + end) / 2
+		if x < vec.sampledBlock(pivot)*rrrBlockSize-vec.rankSamples[pivot] {
+			end = pivot
+		} else {
+			begin = pivot
+		}
+	}
+	var blockID = begin * rrrSampleRate
+	var rank = blockID*rrrBlockSize - vec.rankSamples[begin]
+	var offset = vec.offsetSamples[begin]
+	for {
+		var class = vec.class(blockID)
+		var count0s = rrrBlockSize - class
+		if x < rank+count0s {
+			break
+		}
+		rank += count0s
+		offset += rrrOffsetWidth[class]
+		blockID++
+	}
+	return select64(^vec.block(blockID, offset), x-rank, blockID*rrrBlockSize), nil
+}
+
+// Select returns the position of the x-th occurrence of `b`
+func (vec *RRRVectorData) Select(x uint64, b bool) (uint64, error) {
+	if b {
+		return vec.Select1(x)
+	}
+	return vec.Select0(x)
+}
+
+// NextOne returns the position of the first 1 at or after position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *RRRVectorData) NextOne(i uint64) (uint64, error) {
+	return nextOf(vec, i, true)
+}
+
+// NextZero returns the position of the first 0 at or after position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *RRRVectorData) NextZero(i uint64) (uint64, error) {
+	return nextOf(vec, i, false)
+}
+
+// PrevOne returns the position of the last 1 at or before position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *RRRVectorData) PrevOne(i uint64) (uint64, error) {
+	return prevOf(vec, i, true)
+}
+
+// PrevZero returns the position of the last 0 at or before position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *RRRVectorData) PrevZero(i uint64) (uint64, error) {
+	return prevOf(vec, i, false)
+}
+
+// Size returns size of bit vector
+func (vec *RRRVectorData) Size() uint64 {
+	return vec.size
+}
+
+// NumOfBits returns number of bits that matches with argument in the bit vector.
+func (vec *RRRVectorData) NumOfBits(b bool) uint64 {
+	if b {
+		return vec.numOf1s
+	}
+	return vec.size - vec.numOf1s
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (vec *RRRVectorData) MarshalBinary() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	binary.Write(buffer, binary.LittleEndian, &vec.size)
+	binary.Write(buffer, binary.LittleEndian, &vec.numOf1s)
+	for _, v := range []*BitVectorData{&vec.classes, &vec.offsets} {
+		buf, err := v.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(buf)
+	}
+	return marshalContainer(kindRRR, 0, buffer.Bytes()), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (vec *RRRVectorData) UnmarshalBinary(data []byte) error {
+	_, payload, err := unmarshalContainer(data, kindRRR, true)
+	if err != nil {
+		return err
+	}
+	if uint64(len(payload)) < sizeOfInt64*2 {
+		return ErrorInvalidFormat
+	}
+	vec.size = binary.LittleEndian.Uint64(payload)
+	vec.numOf1s = binary.LittleEndian.Uint64(payload[sizeOfInt64:])
+	buf, err := unmarshalEmbedded(&vec.classes, payload[sizeOfInt64*2:])
+	if err != nil {
+		return err
+	}
+	buf, err = unmarshalEmbedded(&vec.offsets, buf)
+	if err != nil {
+		return err
+	}
+	if len(buf) != 0 || vec.classes.size != vec.numOfBlocks()*rrrClassWidth {
+		return ErrorInvalidFormat
+	}
+	vec.buildSamples()
+	if vec.rankSamples[len(vec.rankSamples)-1] != vec.numOf1s || vec.offsetSamples[len(vec.offsetSamples)-1] != vec.offsets.size {
+		return ErrorInvalidFormat
+	}
+	return nil
+}
+
+// rrrEncode returns offset of the block that has `class` 1s.
+func rrrEncode(bits uint64, class uint64) uint64 {
+	var offset uint64
+	for i := uint64(0); class > 0; i++ {
+		if (bits & (1 << i)) != 0 {
+			offset += binomialTable[rrrBlockSize-1-i][class]
+			class--
+		}
+	}
+	return offset
+}
+
+// rrrDecode returns bits of the block from its offset and class.
+func rrrDecode(offset uint64, class uint64) uint64 {
+	var bits uint64
+	for i := uint64(0); class > 0; i++ {
+		var c = binomialTable[rrrBlockSize-1-i][class]
+		if offset >= c {
+			bits |= 1 << i
+			offset -= c
+			class--
+		}
+	}
+	return bits
+}
+
+// bitWidth returns number of bits required to represent `x`.
+func bitWidth(x uint64) uint64 {
+	var width uint64
+	for x != 0 {
+		width++
+		x >>= 1
+	}
+	return width
+}
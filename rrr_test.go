@@ -0,0 +1,171 @@
+package sbvector
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRRRVector(t *testing.T) {
+	builder := NewVectorBuilder()
+
+	for _, v := range bitCases {
+		builder.Set(v.pos, v.bit)
+	}
+
+	vec, err := builder.BuildRRR()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range bitCases {
+		x, err := vec.Get(v.pos)
+		if err != nil || x != v.bit {
+			t.Error("Expected", v.bit, "got", x)
+		}
+	}
+
+	if size := vec.Size(); size != uint64(6001) {
+		t.Error("Expected", 6001, "got", size)
+	}
+
+	if size := vec.NumOfBits(true); size != uint64(20) {
+		t.Error("Expected", 20, "got", size)
+	}
+
+	for _, v := range rankCases {
+		rank, err := vec.Rank1(v.pos)
+		if err != nil || rank != v.rank {
+			t.Error("Expected", v.rank, "got", rank)
+		}
+		rank, err = vec.Rank0(v.pos)
+		if err != nil || rank != (v.pos-v.rank) {
+			t.Error("Expected", (v.pos - v.rank), "got", rank)
+		}
+	}
+
+	for _, v := range select1Cases {
+		pos, err := vec.Select1(v.index)
+		if err != nil || pos != v.pos {
+			t.Error("Expected", v.pos, "got", pos)
+		}
+	}
+
+	for _, v := range select0Cases {
+		pos, err := vec.Select0(v.index)
+		if err != nil || pos != v.pos {
+			t.Error("Expected", v.pos, "got", pos)
+		}
+	}
+
+	if _, err := vec.Get(6001); err != ErrorOutOfRange {
+		t.Error()
+	}
+	if rank, err := vec.Rank1(6002); err != ErrorOutOfRange || rank != NotFound {
+		t.Error()
+	}
+	if pos, err := vec.Select1(20); err != ErrorOutOfRange || pos != NotFound {
+		t.Error()
+	}
+	if pos, err := vec.Select0(5981); err != ErrorOutOfRange || pos != NotFound {
+		t.Error()
+	}
+}
+
+func TestRRRVectorRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, density := range []int{2, 50, 98} {
+		builder := NewVectorBuilder()
+		rrrBuilder := NewVectorBuilder()
+		for i := 0; i < 20000; i++ {
+			b := r.Intn(100) < density
+			builder.PushBack(b)
+			rrrBuilder.PushBack(b)
+		}
+		expected, _ := builder.Build(true, true)
+		vec, _ := rrrBuilder.BuildRRR()
+		compareVectors(t, expected, vec)
+
+		buf, err := vec.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		vec2, err := NewVectorFromBinary(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := vec2.(*RRRVectorData); !ok {
+			t.Fatal("Expected *RRRVectorData")
+		}
+		compareVectors(t, expected, vec2)
+
+		if err := vec2.UnmarshalBinary(buf[:len(buf)-1]); err == nil {
+			t.Error("Expected error")
+		}
+	}
+}
+
+// compareVectors checks that `vec` answers same as `expected` for every query.
+func compareVectors(t *testing.T, expected SuccinctBitVectorReader, vec SuccinctBitVectorReader) {
+	if expected.Size() != vec.Size() || expected.NumOfBits(true) != vec.NumOfBits(true) {
+		t.Fatal("Expected", expected.Size(), expected.NumOfBits(true), "got", vec.Size(), vec.NumOfBits(true))
+	}
+	for i := uint64(0); i < expected.Size(); i++ {
+		b1, _ := expected.Get(i)
+		b2, err := vec.Get(i)
+		if err != nil || b1 != b2 {
+			t.Fatal("Get", i, "Expected", b1, "got", b2)
+		}
+	}
+	for i := uint64(0); i <= expected.Size(); i++ {
+		r1, _ := expected.Rank1(i)
+		r2, err := vec.Rank1(i)
+		if err != nil || r1 != r2 {
+			t.Fatal("Rank1", i, "Expected", r1, "got", r2)
+		}
+	}
+	for x := uint64(0); x < expected.NumOfBits(true); x++ {
+		p1, _ := expected.Select1(x)
+		p2, err := vec.Select1(x)
+		if err != nil || p1 != p2 {
+			t.Fatal("Select1", x, "Expected", p1, "got", p2)
+		}
+	}
+	for x := uint64(0); x < expected.NumOfBits(false); x++ {
+		p1, _ := expected.Select0(x)
+		p2, err := vec.Select0(x)
+		if err != nil || p1 != p2 {
+			t.Fatal("Select0", x, "Expected", p1, "got", p2)
+		}
+	}
+	for i := uint64(0); i < expected.Size(); i++ {
+		p1, _ := expected.NextOne(i)
+		p2, err := vec.NextOne(i)
+		if err != nil || p1 != p2 {
+			t.Fatal("NextOne", i, "Expected", p1, "got", p2)
+		}
+		p1, _ = expected.NextZero(i)
+		p2, err = vec.NextZero(i)
+		if err != nil || p1 != p2 {
+			t.Fatal("NextZero", i, "Expected", p1, "got", p2)
+		}
+		p1, _ = expected.PrevOne(i)
+		p2, err = vec.PrevOne(i)
+		if err != nil || p1 != p2 {
+			t.Fatal("PrevOne", i, "Expected", p1, "got", p2)
+		}
+		p1, _ = expected.PrevZero(i)
+		p2, err = vec.PrevZero(i)
+		if err != nil || p1 != p2 {
+			t.Fatal("PrevZero", i, "Expected", p1, "got", p2)
+		}
+	}
+	for pos := uint64(0); pos+64 <= expected.Size(); pos += 37 {
+		for _, length := range []uint64{1, 13, 64} {
+			x1, _ := expected.GetBits(pos, length)
+			x2, err := vec.GetBits(pos, length)
+			if err != nil || x1 != x2 {
+				t.Fatal("GetBits", pos, length, "Expected", x1, "got", x2)
+			}
+		}
+	}
+}
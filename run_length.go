@@ -0,0 +1,252 @@
+package sbvector
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// RunLengthVectorData holds information about run-length encoded bit vector.
+// It stores the start position of each run of 1s and number of 1s before each run by Elias-Fano encoding,
+// so that it takes space proportional to the number of runs.
+type RunLengthVectorData struct {
+	starts EliasFano
+	counts EliasFano
+	size   uint64
+}
+
+// NewRunLengthVector returns new run-length encoded bit vector that has same bits as `src`.
+func NewRunLengthVector(src SuccinctBitVector) (*RunLengthVectorData, error) {
+	var starts []uint64
+	var counts = []uint64{0}
+	var size = src.Size()
+	var numOf1s uint64
+	pos, err := src.NextOne(0)
+	for err == nil && pos != NotFound {
+		var end uint64
+		end, err = src.NextZero(pos)
+		if err != nil {
+			break
+		}
+		if end == NotFound {
+			end = size
+		}
+		numOf1s += end - pos
+		starts = append(starts, pos)
+		counts = append(counts, numOf1s)
+		pos = NotFound
+		if end < size {
+			pos, err = src.NextOne(end)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	vec := new(RunLengthVectorData)
+	vec.size = size
+	vec.starts = *newEliasFano(starts, size)
+	vec.counts = *newEliasFano(counts, numOf1s+1)
+	return vec, nil
+}
+
+// NumOfRuns returns number of the runs of 1s.
+func (vec *RunLengthVectorData) NumOfRuns() uint64 {
+	return vec.starts.length
+}
+
+// run returns number of the runs that start at or before position `i`.
+func (vec *RunLengthVectorData) run(i uint64) uint64 {
+	return vec.starts.lowerBound(i + 1)
+}
+
+// runEnd returns the end of the k-th run.
+func (vec *RunLengthVectorData) runEnd(k uint64) uint64 {
+	return vec.starts.access(k) + vec.counts.access(k+1) - vec.counts.access(k)
+}
+
+// Get returns value from bit vector by index.
+func (vec *RunLengthVectorData) Get(i uint64) (bool, error) {
+	if i >= vec.size {
+		return false, ErrorOutOfRange
+	}
+	var k = vec.run(i)
+	return k > 0 && i < vec.runEnd(k-1), nil
+}
+
+// GetBits returns bits from bit vector.
+func (vec *RunLengthVectorData) GetBits(pos uint64, length uint64) (uint64, error) {
+	if (pos + length) > vec.size {
+		return NotFound, ErrorOutOfRange
+	}
+	var result uint64
+	var k = vec.run(pos)
+	if k > 0 {
+		k--
+	}
+	for ; k < vec.starts.length; k++ {
+		var start = vec.starts.access(k)
+		if start >= pos+length {
+			break
+		}
+		var end = vec.runEnd(k)
+		if start < pos {
+			start = pos
+		}
+		if end > pos+length {
+			end = pos + length
+		}
+		if start < end {
+			result |= mask(^uint64(0), end-start) << (start - pos)
+		}
+	}
+	return result, nil
+}
+
+// Rank1 returns number of the bits equal to `1` up to positin `i`
+func (vec *RunLengthVectorData) Rank1(i uint64) (uint64, error) {
+	if i > vec.size {
+		return NotFound, ErrorOutOfRange
+	}
+	var k = vec.starts.lowerBound(i)
+	if k == 0 {
+		return 0, nil
+	}
+	var rank = vec.counts.access(k - 1)
+	var start = vec.starts.access(k - 1)
+	var length = vec.counts.access(k) - rank
+	if i-start < length {
+		return rank + (i - start), nil
+	}
+	return rank + length, nil
+}
+
+// Rank0 returns number of the bits equal to `0` up to positin `i`
+func (vec *RunLengthVectorData) Rank0(i uint64) (uint64, error) {
+	rank, err := vec.Rank1(i)
+	if err != nil {
+		return rank, err
+	}
+	return i - rank, nil
+}
+
+// Rank returns number of the bits equal to `b` up to positin `i`
+func (vec *RunLengthVectorData) Rank(i uint64, b bool) (uint64, error) {
+	if b {
+		return vec.Rank1(i)
+	}
+	return vec.Rank0(i)
+}
+
+// Select1 returns the position of the x-th occurence of 1
+func (vec *RunLengthVectorData) Select1(x uint64) (uint64, error) {
+	if vec.NumOfBits(true) <= x {
+		return NotFound, ErrorOutOfRange
+	}
+	var k = vec.counts.lowerBound(x+1) - 1
+	return vec.starts.access(k) + (x - vec.counts.access(k)), nil
+}
+
+// Select0 returns the position of the x-th occurence of 0
+func (vec *RunLengthVectorData) Select0(x uint64) (uint64, error) {
+	if vec.NumOfBits(false) <= x {
+		return NotFound, ErrorOutOfRange
+	}
+	// Find number of the runs preceded by x 0s or less, the x-th 0 follows the last of them.
+	var begin uint64
+	var end = vec.starts.length
+	for begin < end {
+		var pivot = (begin + end) / 2
+		if vec.starts.access(pivot)-vec.counts.access(pivot) > x {
+			end = pivot
+		} else {
+			begin = pivot + 1
+		}
+	}
+	return x + vec.counts.access(begin), nil
+}
+
+// Select returns the position of the x-th occurrence of `b`
+func (vec *RunLengthVectorData) Select(x uint64, b bool) (uint64, error) {
+	if b {
+		return vec.Select1(x)
+	}
+	return vec.Select0(x)
+}
+
+// NextOne returns the position of the first 1 at or after position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *RunLengthVectorData) NextOne(i uint64) (uint64, error) {
+	return nextOf(vec, i, true)
+}
+
+// NextZero returns the position of the first 0 at or after position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *RunLengthVectorData) NextZero(i uint64) (uint64, error) {
+	return nextOf(vec, i, false)
+}
+
+// PrevOne returns the position of the last 1 at or before position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *RunLengthVectorData) PrevOne(i uint64) (uint64, error) {
+	return prevOf(vec, i, true)
+}
+
+// PrevZero returns the position of the last 0 at or before position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *RunLengthVectorData) PrevZero(i uint64) (uint64, error) {
+	return prevOf(vec, i, false)
+}
+
+// Size returns size of bit vector
+func (vec *RunLengthVectorData) Size() uint64 {
+	return vec.size
+}
+
+// NumOfBits returns number of bits that matches with argument in the bit vector.
+func (vec *RunLengthVectorData) NumOfBits(b bool) uint64 {
+	var numOf1s = vec.counts.access(vec.counts.length - 1)
+	if b {
+		return numOf1s
+	}
+	return vec.size - numOf1s
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (vec *RunLengthVectorData) MarshalBinary() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	binary.Write(buffer, binary.LittleEndian, &vec.size)
+	for _, ef := range []*EliasFano{&vec.starts, &vec.counts} {
+		buf, err := ef.marshalPayload()
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(buf)
+	}
+	return marshalContainer(kindRunLength, 0, buffer.Bytes()), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (vec *RunLengthVectorData) UnmarshalBinary(data []byte) error {
+	_, payload, err := unmarshalContainer(data, kindRunLength, true)
+	if err != nil {
+		return err
+	}
+	if uint64(len(payload)) < sizeOfInt64 {
+		return ErrorInvalidFormat
+	}
+	vec.size = binary.LittleEndian.Uint64(payload)
+	buf, err := vec.starts.unmarshal(payload[sizeOfInt64:])
+	if err != nil {
+		return err
+	}
+	buf, err = vec.counts.unmarshal(buf)
+	if err != nil {
+		return err
+	}
+	if len(buf) != 0 || vec.counts.length != vec.starts.length+1 || vec.counts.access(0) != 0 {
+		return ErrorInvalidFormat
+	}
+	if vec.starts.length > 0 && vec.runEnd(vec.starts.length-1) > vec.size {
+		return ErrorInvalidFormat
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package sbvector
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRunLengthVector(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, size := range []int{0, 1, 100, 20000} {
+		for _, first := range []bool{false, true} {
+			builder := NewVectorBuilder()
+			var b = first
+			for builder.Size() < uint64(size) {
+				for n := r.Intn(200) + 1; n > 0 && builder.Size() < uint64(size); n-- {
+					builder.PushBack(b)
+				}
+				b = !b
+			}
+			expected, _ := builder.Build(true, true)
+			vec, err := NewRunLengthVector(expected)
+			if err != nil {
+				t.Fatal(err)
+			}
+			compareVectors(t, expected, vec)
+
+			buf, err := vec.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			vec2, err := NewVectorFromBinary(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := vec2.(*RunLengthVectorData); !ok {
+				t.Fatal("Expected *RunLengthVectorData")
+			}
+			compareVectors(t, expected, vec2)
+			if err := vec2.UnmarshalBinary(buf[:len(buf)-1]); err == nil {
+				t.Error("Expected error")
+			}
+		}
+	}
+
+	vec, _ := NewVectorFromString("0011100111")
+	rl, _ := NewRunLengthVector(vec)
+	if n := rl.NumOfRuns(); n != 2 {
+		t.Error("Expected", 2, "got", n)
+	}
+	if _, err := rl.Get(10); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+	if _, err := rl.Select1(6); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+	if _, err := rl.Select0(4); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+}
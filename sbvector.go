@@ -2,6 +2,7 @@
 Package sbvector is implementation of succinct bit vector for Go.
 
 Synopsis
+
 	import (
 		"github.com/hideo55/go-sbvector"
 	)
@@ -52,6 +53,11 @@ type BitVectorData struct {
 type SuccinctBitVector interface {
 	encoding.BinaryMarshaler
 	encoding.BinaryUnmarshaler
+	SuccinctBitVectorReader
+}
+
+// SuccinctBitVectorReader is interface of the queries of succinct bit vector.
+type SuccinctBitVectorReader interface {
 	Get(i uint64) (bool, error)
 	GetBits(pos uint64, length uint64) (uint64, error)
 	Rank1(i uint64) (uint64, error)
@@ -60,12 +66,12 @@ type SuccinctBitVector interface {
 	Select1(x uint64) (uint64, error)
 	Select0(x uint64) (uint64, error)
 	Select(x uint64, b bool) (uint64, error)
+	NextOne(i uint64) (uint64, error)
+	NextZero(i uint64) (uint64, error)
+	PrevOne(i uint64) (uint64, error)
+	PrevZero(i uint64) (uint64, error)
 	Size() uint64
 	NumOfBits(b bool) uint64
-	set(i uint64, val bool)
-	pushBack(b bool)
-	pushBackBits(x uint64, length uint64)
-	build(enableFasterSelect1 bool, enableFasterSelect0 bool)
 }
 
 const (
@@ -78,8 +84,10 @@ const (
 	lBlockSize  uint64 = 512
 	blockRate   uint64 = 8
 	minimumSize uint64 = 40
-	sizeOfInt32 uint64 = 4
-	sizeOfInt64 uint64 = 8
+	// bitVectorFieldsSize is size of the fields at the head of the payload of BitVectorData.
+	bitVectorFieldsSize uint64 = 48
+	sizeOfInt32         uint64 = 4
+	sizeOfInt64         uint64 = 8
 	// NotFound indicates `value not found`
 	NotFound uint64 = 0xFFFFFFFFFFFFFFFF
 )
@@ -150,11 +158,29 @@ var (
 	ErrorInvalidLength = errors.New("UnmarshalBinary: invalid length of slice")
 	// ErrorInvalidFormat indicates that binary format is invalid.
 	ErrorInvalidFormat = errors.New("UnmarshalBinary: invalid binary format")
+	// ErrorBadMagic indicates that binary data does not start with the magic number.
+	ErrorBadMagic = errors.New("UnmarshalBinary: bad magic number")
+	// ErrorUnsupportedVersion indicates that version of binary format is not supported.
+	ErrorUnsupportedVersion = errors.New("UnmarshalBinary: unsupported format version")
+	// ErrorChecksumMismatch indicates that checksum of binary data does not match.
+	ErrorChecksumMismatch = errors.New("UnmarshalBinary: checksum mismatch")
 )
 
 // NewVectorFromBinary returns new succinct bit vector that initialize by binary data.
 func NewVectorFromBinary(data []byte) (SuccinctBitVector, error) {
-	vec := new(BitVectorData)
+	var vec SuccinctBitVector
+	switch binaryKind(data) {
+	case kindRRR:
+		vec = new(RRRVectorData)
+	case kindSparse:
+		vec = new(SparseVectorData)
+	case kindRunLength:
+		vec = new(RunLengthVectorData)
+	case kindHybrid:
+		vec = new(HybridVectorData)
+	default:
+		vec = new(BitVectorData)
+	}
 	err := vec.UnmarshalBinary(data)
 	return vec, err
 }
@@ -167,7 +193,7 @@ func (vec *BitVectorData) Get(i uint64) (bool, error) {
 	return (vec.blocks[i/sBlockSize] & (1 << (i % sBlockSize))) != 0, nil
 }
 
-//GetBits returns bits from bit vector.
+// GetBits returns bits from bit vector.
 func (vec *BitVectorData) GetBits(pos uint64, length uint64) (uint64, error) {
 	if (pos + length) > vec.size {
 		return NotFound, ErrorOutOfRange
@@ -230,6 +256,35 @@ func (vec *BitVectorData) pushBackBits(x uint64, length uint64) {
 	vec.size += length
 }
 
+// appendVector adds all bits of `src` to the end, 64 bits at a time.
+func (vec *BitVectorData) appendVector(src SuccinctBitVector) error {
+	words, err := vectorWords(src)
+	if err != nil {
+		return err
+	}
+	var size = src.Size()
+	for i := uint64(0); i*sBlockSize < size; i++ {
+		var length = size - i*sBlockSize
+		if length > sBlockSize {
+			length = sBlockSize
+		}
+		vec.pushBackBits(wordAt(words, size, i), length)
+	}
+	return nil
+}
+
+// setBits overwrites `length` bits from position `pos` by `x`. `x` must fit in `length` bits.
+func (vec *BitVectorData) setBits(pos uint64, x uint64, length uint64) {
+	var blockIdx1 = pos / sBlockSize
+	var blockOffset1 = pos % sBlockSize
+	var m = mask(^uint64(0), length)
+	vec.blocks[blockIdx1] = (vec.blocks[blockIdx1] &^ (m << blockOffset1)) | (x << blockOffset1)
+	if (blockOffset1 + length) > sBlockSize {
+		var shift = sBlockSize - blockOffset1
+		vec.blocks[blockIdx1+1] = (vec.blocks[blockIdx1+1] &^ (m >> shift)) | (x >> shift)
+	}
+}
+
 func (vec *BitVectorData) build(enableFasterSelect1 bool, enableFasterSelect0 bool) {
 	var blockNum = uint64(len(vec.blocks))
 	var numOf1s = lBlockSize
@@ -332,31 +387,50 @@ func (vec *BitVectorData) Rank1(i uint64) (uint64, error) {
 	if i > vec.size {
 		return NotFound, ErrorOutOfRange
 	}
-	var rankID = i / lBlockSize
+	var rank = &vec.ranks[i/lBlockSize]
+	return rank.abs() + vec.rankInLargeBlock(rank, i), nil
+}
+
+// rankInLargeBlock returns number of the bits equal to `1` from the head of the large block of `rank` up to position `i`.
+func (vec *BitVectorData) rankInLargeBlock(rank *rankIndex, i uint64) uint64 {
 	var blockID = i / sBlockSize
 	var r = i % sBlockSize
+	var offset = rank.relOf(blockID % blockRate)
+	if r != 0 {
+		offset += popcount.Count(vec.blocks[blockID] & ((1 << r) - 1))
+	}
+	return offset
+}
 
-	var rank = vec.ranks[rankID]
-	var offset = rank.abs()
-	switch blockID % blockRate {
-	case 1:
-		offset += rank.rel1()
-	case 2:
-		offset += rank.rel2()
-	case 3:
-		offset += rank.rel3()
-	case 4:
-		offset += rank.rel4()
-	case 5:
-		offset += rank.rel5()
-	case 6:
-		offset += rank.rel6()
-	case 7:
-		offset += rank.rel7()
-	default:
+// PopcountRange returns number of the bits equal to `1` in range [l, r).
+func (vec *BitVectorData) PopcountRange(l uint64, r uint64) (uint64, error) {
+	if l > r || r > vec.size {
+		return NotFound, ErrorOutOfRange
+	}
+	if l/sBlockSize == r/sBlockSize {
+		if l == r {
+			return 0, nil
+		}
+		return popcount.Count(mask(vec.blocks[l/sBlockSize]>>(l%sBlockSize), r-l)), nil
+	}
+	var rank = &vec.ranks[r/lBlockSize]
+	if l/lBlockSize == r/lBlockSize {
+		return vec.rankInLargeBlock(rank, r) - vec.rankInLargeBlock(rank, l), nil
+	}
+	var rankL = &vec.ranks[l/lBlockSize]
+	return rank.abs() + vec.rankInLargeBlock(rank, r) - rankL.abs() - vec.rankInLargeBlock(rankL, l), nil
+}
+
+// RankRange returns number of the bits equal to `b` in range [l, r).
+func (vec *BitVectorData) RankRange(l uint64, r uint64, b bool) (uint64, error) {
+	count, err := vec.PopcountRange(l, r)
+	if err != nil {
+		return count, err
+	}
+	if b {
+		return count, nil
 	}
-	offset += popcount.Count(vec.blocks[blockID] & ((1 << r) - 1))
-	return offset, nil
+	return (r - l) - count, nil
 }
 
 // Rank0 returns number of the bits equal to `0` up to positin `i`
@@ -411,7 +485,11 @@ func (vec *BitVectorData) Select1(x uint64) (uint64, error) {
 			}
 		}
 	}
-	var rankID = begin
+	return vec.select1InLargeBlock(begin, x), nil
+}
+
+// select1InLargeBlock returns the position of the x-th occurence of 1, that is in the large block `rankID`.
+func (vec *BitVectorData) select1InLargeBlock(rankID uint64, x uint64) uint64 {
 	var rank = &vec.ranks[rankID]
 	var rankOffset = rank.abs()
 	x -= rankOffset
@@ -444,7 +522,7 @@ func (vec *BitVectorData) Select1(x uint64) (uint64, error) {
 		blockID += 7
 		x -= rank.rel7()
 	}
-	return select64(vec.blocks[blockID], x, blockID*sBlockSize), nil
+	return select64(vec.blocks[blockID], x, blockID*sBlockSize)
 }
 
 // Select0 returns the position of the x-th occurence of 0
@@ -527,6 +605,81 @@ func (vec *BitVectorData) Select(x uint64, b bool) (uint64, error) {
 	return vec.Select0(x)
 }
 
+// NextOne returns the position of the first 1 at or after position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *BitVectorData) NextOne(i uint64) (uint64, error) {
+	return vec.next(i, true)
+}
+
+// NextZero returns the position of the first 0 at or after position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *BitVectorData) NextZero(i uint64) (uint64, error) {
+	return vec.next(i, false)
+}
+
+// PrevOne returns the position of the last 1 at or before position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *BitVectorData) PrevOne(i uint64) (uint64, error) {
+	return vec.prev(i, true)
+}
+
+// PrevZero returns the position of the last 0 at or before position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *BitVectorData) PrevZero(i uint64) (uint64, error) {
+	return vec.prev(i, false)
+}
+
+func (vec *BitVectorData) next(i uint64, b bool) (uint64, error) {
+	if i > vec.size {
+		return NotFound, ErrorOutOfRange
+	}
+	var blockID = i / sBlockSize
+	if blockID >= uint64(len(vec.blocks)) {
+		return NotFound, nil
+	}
+	var x = vec.blocks[blockID]
+	if !b {
+		x = ^x
+	}
+	x &= ^((uint64(1) << (i % sBlockSize)) - 1)
+	if x != 0 {
+		var pos = blockID*sBlockSize + uint64(countTrailingZeros(x))
+		if pos >= vec.size {
+			return NotFound, nil
+		}
+		return pos, nil
+	}
+	var nextBlock = (blockID + 1) * sBlockSize
+	if nextBlock >= vec.size {
+		return NotFound, nil
+	}
+	rank, _ := vec.Rank(nextBlock, b)
+	if rank >= vec.NumOfBits(b) {
+		return NotFound, nil
+	}
+	return vec.Select(rank, b)
+}
+
+func (vec *BitVectorData) prev(i uint64, b bool) (uint64, error) {
+	if i >= vec.size {
+		return NotFound, ErrorOutOfRange
+	}
+	var blockID = i / sBlockSize
+	var x = vec.blocks[blockID]
+	if !b {
+		x = ^x
+	}
+	x &= (uint64(2) << (i % sBlockSize)) - 1
+	if x != 0 {
+		return blockID*sBlockSize + mostSignificantBit(x), nil
+	}
+	rank, _ := vec.Rank(blockID*sBlockSize, b)
+	if rank == 0 {
+		return NotFound, nil
+	}
+	return vec.Select(rank-1, b)
+}
+
 // Size returns size of bit vector
 func (vec *BitVectorData) Size() uint64 {
 	return vec.size
@@ -542,50 +695,108 @@ func (vec *BitVectorData) NumOfBits(b bool) uint64 {
 
 // MarshalBinary implements the encoding.BinaryMarshaler interface.
 func (vec *BitVectorData) MarshalBinary() ([]byte, error) {
-	buffer := new(bytes.Buffer)
+	buffer := bytes.NewBuffer(make([]byte, 0, vec.serializedSize()))
+	if _, err := vec.WriteTo(buffer); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
 
-	blockNum := uint32(len(vec.blocks))
-	rankTableSize := uint32(len(vec.ranks))
-	select1TableSize := uint32(len(vec.select1Table))
-	select0TableSize := uint32(len(vec.select0Table))
+// serializedSize returns size of the binary image of the bit vector.
+func (vec *BitVectorData) serializedSize() uint64 {
+	return headerSize + vec.payloadSize() + trailerSize
+}
 
+// payloadSize returns size of the payload in the binary image of the bit vector.
+func (vec *BitVectorData) payloadSize() uint64 {
 	var tmpRankIndex rankIndex
 	sizeOfRI := uint64(unsafe.Sizeof(tmpRankIndex))
 
-	var serializedSize uint64
-	serializedSize = uint64(blockNum) * sizeOfInt64
-	serializedSize += uint64(rankTableSize) * sizeOfRI
-	serializedSize += uint64(select1TableSize) * sizeOfInt64
-	serializedSize += uint64(select0TableSize) * sizeOfInt64
-	serializedSize += sizeOfInt64 * 3 /* Sizeof(serializedSize) + Sizeof(vec.size) + Sizeof(vec.numOf1s) */
-	serializedSize += sizeOfInt32 * 4 /* Sizeof(blockNum) + Sizeof(rankTableSize) + Sizeof(select1TableSize) + Sizeof(select0TableSize) */
-	binary.Write(buffer, binary.LittleEndian, &serializedSize)
-	binary.Write(buffer, binary.LittleEndian, &vec.size)
-	binary.Write(buffer, binary.LittleEndian, &vec.numOf1s)
-
-	binary.Write(buffer, binary.LittleEndian, &blockNum)
-	for _, block := range vec.blocks {
-		binary.Write(buffer, binary.LittleEndian, &block)
-	}
+	var payloadSize = bitVectorFieldsSize
+	payloadSize += uint64(len(vec.blocks)) * sizeOfInt64
+	payloadSize += uint64(len(vec.ranks)) * sizeOfRI
+	payloadSize += uint64(len(vec.select1Table)) * sizeOfInt64
+	payloadSize += uint64(len(vec.select0Table)) * sizeOfInt64
+	return payloadSize
+}
 
-	binary.Write(buffer, binary.LittleEndian, &rankTableSize)
-	for _, ri := range vec.ranks {
-		buf, _ := ri.MarshalBinary()
-		binary.Write(buffer, binary.LittleEndian, buf)
+// indexFlags returns the flags of indexes that the bit vector has.
+func (vec *BitVectorData) indexFlags() uint8 {
+	var flags uint8
+	if len(vec.ranks) > 0 {
+		flags |= flagRankIndex
 	}
-	binary.Write(buffer, binary.LittleEndian, &select1TableSize)
-	for _, s1 := range vec.select1Table {
-		binary.Write(buffer, binary.LittleEndian, &s1)
+	if len(vec.select1Table) > 0 {
+		flags |= flagSelect1Index
 	}
-	binary.Write(buffer, binary.LittleEndian, &select0TableSize)
-	for _, s0 := range vec.select0Table {
-		binary.Write(buffer, binary.LittleEndian, &s0)
+	if len(vec.select0Table) > 0 {
+		flags |= flagSelect0Index
 	}
-	return buffer.Bytes(), nil
+	return flags
 }
 
 // UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
 func (vec *BitVectorData) UnmarshalBinary(data []byte) error {
+	return vec.unmarshal(data, false)
+}
+
+// unmarshal restores the bit vector from `data`.
+// If `noCopy` is true, blocks and indexes alias `data` as long as the alignment allows it,
+// and the checksum is not verified.
+func (vec *BitVectorData) unmarshal(data []byte, noCopy bool) error {
+	if hasMagic(data) {
+		return vec.unmarshalPayload(data, noCopy)
+	}
+	if uint64(len(data)) >= sizeOfInt64 && !isLegacySize(binary.LittleEndian.Uint64(data)) {
+		return ErrorBadMagic
+	}
+	return vec.unmarshalLegacy(data, noCopy)
+}
+
+// unmarshalPayload restores the bit vector from the image with the header.
+func (vec *BitVectorData) unmarshalPayload(data []byte, noCopy bool) error {
+	header, payload, err := unmarshalContainer(data, kindBitVector, !noCopy)
+	if err != nil {
+		return err
+	}
+	if uint64(len(payload)) < bitVectorFieldsSize {
+		return ErrorInvalidFormat
+	}
+	var fields [bitVectorFieldsSize / sizeOfInt64]uint64
+	for i := range fields {
+		fields[i] = binary.LittleEndian.Uint64(payload[uint64(i)*sizeOfInt64:])
+	}
+	size, numOf1s, blockNum, rankTableSize, select1TableSize, select0TableSize := fields[0], fields[1], fields[2], fields[3], fields[4], fields[5]
+	var payloadSize = uint64(len(payload))
+	if blockNum > payloadSize || rankTableSize > payloadSize || select1TableSize > payloadSize || select0TableSize > payloadSize {
+		return ErrorInvalidFormat
+	}
+	var sizeOfRankIndex = uint64(binarySize)
+	var offset = bitVectorFieldsSize
+	if offset+(blockNum+select1TableSize+select0TableSize)*sizeOfInt64+rankTableSize*sizeOfRankIndex != payloadSize {
+		return ErrorInvalidFormat
+	}
+	if size > blockNum*sBlockSize || numOf1s > size {
+		return ErrorInvalidFormat
+	}
+
+	vec.size = size
+	vec.numOf1s = numOf1s
+	vec.blocks = decodeUint64s(payload[offset:offset+blockNum*sizeOfInt64], noCopy)
+	offset += blockNum * sizeOfInt64
+	vec.ranks = decodeRankIndexes(payload[offset:offset+rankTableSize*sizeOfRankIndex], noCopy)
+	offset += rankTableSize * sizeOfRankIndex
+	vec.select1Table = decodeUint64s(payload[offset:offset+select1TableSize*sizeOfInt64], noCopy)
+	offset += select1TableSize * sizeOfInt64
+	vec.select0Table = decodeUint64s(payload[offset:], noCopy)
+	if header.flags != vec.indexFlags() {
+		return ErrorInvalidFormat
+	}
+	return nil
+}
+
+// unmarshalLegacy restores the bit vector from the image without the header.
+func (vec *BitVectorData) unmarshalLegacy(data []byte, noCopy bool) error {
 	buf := data
 	if uint64(len(data)) < minimumSize {
 		return ErrorInvalidLength
@@ -609,64 +820,101 @@ func (vec *BitVectorData) UnmarshalBinary(data []byte) error {
 
 	buf = data[offset : offset+sizeOfInt32]
 	offset += sizeOfInt32
-	blockNum := binary.LittleEndian.Uint32(buf)
+	blockNum := uint64(binary.LittleEndian.Uint32(buf))
 
-	if (offset + uint64(blockNum)*sizeOfInt64) > uint64(len(data)) {
+	if (offset + blockNum*sizeOfInt64 + sizeOfInt32) > uint64(len(data)) {
 		return ErrorInvalidFormat
 	}
-
-	vec.blocks = make([]uint64, blockNum)
-	for i := uint32(0); i < blockNum; i++ {
-		buf = data[offset : offset+sizeOfInt64]
-		offset += sizeOfInt64
-		vec.blocks[i] = binary.LittleEndian.Uint64(buf)
-	}
+	vec.blocks = decodeUint64s(data[offset:offset+blockNum*sizeOfInt64], noCopy)
+	offset += blockNum * sizeOfInt64
 
 	buf = data[offset : offset+sizeOfInt32]
 	offset += sizeOfInt32
-	rankTableSize := binary.LittleEndian.Uint32(buf)
+	rankTableSize := uint64(binary.LittleEndian.Uint32(buf))
 	var tmpRankIndex rankIndex
 	sizeOfRankIndex := uint64(unsafe.Sizeof(tmpRankIndex))
-	if (offset + sizeOfRankIndex*uint64(rankTableSize)) > uint64(len(data)) {
+	if (offset + sizeOfRankIndex*rankTableSize + sizeOfInt32) > uint64(len(data)) {
 		return ErrorInvalidFormat
 	}
-	vec.ranks = make([]rankIndex, rankTableSize)
-	sizeOfRI := unsafe.Sizeof(vec.ranks[0])
-	for i := uint32(0); i < rankTableSize; i++ {
-		buf = data[offset : offset+uint64(sizeOfRI)]
-		offset += uint64(sizeOfRI)
-		vec.ranks[i].UnmarshalBinary(buf)
-	}
+	vec.ranks = decodeRankIndexes(data[offset:offset+sizeOfRankIndex*rankTableSize], noCopy)
+	offset += sizeOfRankIndex * rankTableSize
 
 	buf = data[offset : offset+sizeOfInt32]
 	offset += sizeOfInt32
-	select1TableSize := binary.LittleEndian.Uint32(buf)
-	if (offset + uint64(select1TableSize)*sizeOfInt64) > uint64(len(data)) {
+	select1TableSize := uint64(binary.LittleEndian.Uint32(buf))
+	if (offset + select1TableSize*sizeOfInt64 + sizeOfInt32) > uint64(len(data)) {
 		return ErrorInvalidFormat
 	}
-	vec.select1Table = make([]uint64, select1TableSize)
-	for i := uint32(0); i < select1TableSize; i++ {
-		buf = data[offset : offset+sizeOfInt64]
-		offset += sizeOfInt64
-		vec.select1Table[i] = binary.LittleEndian.Uint64(buf)
-	}
+	vec.select1Table = decodeUint64s(data[offset:offset+select1TableSize*sizeOfInt64], noCopy)
+	offset += select1TableSize * sizeOfInt64
 
 	buf = data[offset : offset+sizeOfInt32]
 	offset += sizeOfInt32
-	select0TableSize := binary.LittleEndian.Uint32(buf)
-	if (offset + uint64(select0TableSize)*sizeOfInt64) != uint64(len(data)) {
+	select0TableSize := uint64(binary.LittleEndian.Uint32(buf))
+	if (offset + select0TableSize*sizeOfInt64) != uint64(len(data)) {
 		return ErrorInvalidFormat
 	}
-	vec.select0Table = make([]uint64, select0TableSize)
-	for i := uint32(0); i < select0TableSize; i++ {
-		buf = data[offset : offset+sizeOfInt64]
-		offset += sizeOfInt64
-		vec.select0Table[i] = binary.LittleEndian.Uint64(buf)
-	}
+	vec.select0Table = decodeUint64s(data[offset:], noCopy)
 
 	return nil
 }
 
+// decodeUint64s returns little endian uint64 values in `data`.
+// If `noCopy` is true, the result aliases `data` as long as the alignment allows it.
+func decodeUint64s(data []byte, noCopy bool) []uint64 {
+	if noCopy {
+		if s := aliasUint64s(data); s != nil {
+			return s
+		}
+	}
+	s := make([]uint64, uint64(len(data))/sizeOfInt64)
+	for i := range s {
+		s[i] = binary.LittleEndian.Uint64(data[uint64(i)*sizeOfInt64:])
+	}
+	return s
+}
+
+// decodeRankIndexes returns rank indexes in `data`.
+// If `noCopy` is true, the result aliases `data` as long as the alignment allows it.
+func decodeRankIndexes(data []byte, noCopy bool) []rankIndex {
+	if noCopy {
+		if s := aliasRankIndexes(data); s != nil {
+			return s
+		}
+	}
+	s := make([]rankIndex, len(data)/binarySize)
+	for i := range s {
+		s[i].UnmarshalBinary(data[i*binarySize : (i+1)*binarySize])
+	}
+	return s
+}
+
+// newVectorFromBlocks returns new succinct bit vector that holds `blocks`, and builds indexes for it.
+// Bits of `blocks` beyond `size` must be 0.
+func newVectorFromBlocks(blocks []uint64, size uint64) *BitVectorData {
+	vec := new(BitVectorData)
+	vec.blocks = blocks
+	vec.size = size
+	vec.build(true, true)
+	return vec
+}
+
+// unmarshalEmbedded restores `vec` from the serialized image at the head of `data`,
+// and returns the rest of `data`.
+func unmarshalEmbedded(vec *BitVectorData, data []byte) ([]byte, error) {
+	size, err := imageSize(data)
+	if err != nil {
+		return nil, err
+	}
+	if size > uint64(len(data)) {
+		return nil, ErrorInvalidLength
+	}
+	if err := vec.UnmarshalBinary(data[:size]); err != nil {
+		return nil, err
+	}
+	return data[size:], nil
+}
+
 func countTrailingZeros(x uint64) uint8 {
 	return uint8(popcount.Count((x & (-x)) - 1))
 }
@@ -686,6 +934,42 @@ func select64(block uint64, i uint64, base uint64) uint64 {
 	return base + uint64(selectTable[i][block&0xFF])
 }
 
+// mostSignificantBit returns the position of the highest 1 in `x`. `x` must not be 0.
+func mostSignificantBit(x uint64) uint64 {
+	var pos uint64
+	for _, shift := range [...]uint64{32, 16, 8, 4, 2, 1} {
+		if (x >> shift) != 0 {
+			x >>= shift
+			pos += shift
+		}
+	}
+	return pos
+}
+
+// nextOf returns the position of the first `b` at or after position `i` by Rank and Select.
+func nextOf(vec SuccinctBitVector, i uint64, b bool) (uint64, error) {
+	rank, err := vec.Rank(i, b)
+	if err != nil {
+		return NotFound, err
+	}
+	if rank >= vec.NumOfBits(b) {
+		return NotFound, nil
+	}
+	return vec.Select(rank, b)
+}
+
+// prevOf returns the position of the last `b` at or before position `i` by Rank and Select.
+func prevOf(vec SuccinctBitVector, i uint64, b bool) (uint64, error) {
+	if i >= vec.Size() {
+		return NotFound, ErrorOutOfRange
+	}
+	rank, _ := vec.Rank(i+1, b)
+	if rank == 0 {
+		return NotFound, nil
+	}
+	return vec.Select(rank-1, b)
+}
+
 func mask(x uint64, pos uint64) uint64 {
 	return x & ((uint64(1) << pos) - 1)
 }
@@ -1,5 +1,9 @@
 package sbvector
 
+import (
+	"errors"
+)
+
 // BitVectorBuilderData holds bit vector data to build.
 type BitVectorBuilderData struct {
 	vec *BitVectorData
@@ -11,9 +15,13 @@ type SuccinctBitVectorBuilder interface {
 	Get(i uint64) (bool, error)
 	PushBack(b bool)
 	PushBackBits(x uint64, length uint64)
+	AppendVector(vec SuccinctBitVector) error
 	GetBits(pos uint64, length uint64) (uint64, error)
 	Size() uint64
 	Build(enableFasterSelect1 bool, enableFasterSelect0 bool) (SuccinctBitVector, error)
+	BuildParallel(enableFasterSelect1 bool, enableFasterSelect0 bool, numOfWorkers int) (SuccinctBitVector, error)
+	BuildRRR() (SuccinctBitVector, error)
+	BuildSparse() (SuccinctBitVector, error)
 }
 
 // NewVectorBuilder returns new succinct bit vector builder.
@@ -24,9 +32,19 @@ func NewVectorBuilder() SuccinctBitVectorBuilder {
 }
 
 // NewVectorBuilderWithInit returns new succinct bit vector builder(initialize by argument).
+// The bits of `vec` are copied, so that `vec` is not modified by the builder.
 func NewVectorBuilderWithInit(vec SuccinctBitVector) SuccinctBitVectorBuilder {
 	builder := new(BitVectorBuilderData)
-	builder.vec = vec.(*BitVectorData)
+	builder.vec = new(BitVectorData)
+	switch v := vec.(type) {
+	case nil:
+	case *BitVectorData:
+		builder.vec.blocks = make([]uint64, len(v.blocks))
+		copy(builder.vec.blocks, v.blocks)
+		builder.vec.size = v.size
+	default:
+		builder.vec.appendVector(v)
+	}
 	return builder
 }
 
@@ -50,6 +68,11 @@ func (builder *BitVectorBuilderData) PushBackBits(x uint64, length uint64) {
 	builder.vec.pushBackBits(x, length)
 }
 
+// AppendVector adds all bits of `vec` to the bit vector
+func (builder *BitVectorBuilderData) AppendVector(vec SuccinctBitVector) error {
+	return builder.vec.appendVector(vec)
+}
+
 //GetBits returns bits from bit vector
 func (builder *BitVectorBuilderData) GetBits(pos uint64, length uint64) (uint64, error) {
 	return builder.vec.GetBits(pos, length)
@@ -69,3 +92,103 @@ func (builder *BitVectorBuilderData) Build(enableFasterSelect1 bool, enableFaste
 	builder.vec = new(BitVectorData)
 	return vec, nil
 }
+
+// BuildParallel creates indexes for succinct bit vector same as Build, by `numOfWorkers` goroutines.
+// If `numOfWorkers` is 0 or less, runtime.NumCPU() goroutines are used.
+func (builder *BitVectorBuilderData) BuildParallel(enableFasterSelect1 bool, enableFasterSelect0 bool, numOfWorkers int) (SuccinctBitVector, error) {
+	builder.vec.buildParallel(enableFasterSelect1, enableFasterSelect0, numOfWorkers)
+	vec := builder.vec
+	builder.vec = new(BitVectorData)
+	return vec, nil
+}
+
+// BuildRRR creates succinct bit vector compressed by RRR encoding.
+// It takes less space than the vector created by Build if the bits are skewed.
+func (builder *BitVectorBuilderData) BuildRRR() (SuccinctBitVector, error) {
+	vec := newRRRVector(builder.vec)
+	builder.vec = new(BitVectorData)
+	return vec, nil
+}
+
+// BuildSparse creates succinct bit vector that stores only the positions of 1s by Elias-Fano encoding.
+// It takes less space than the vector created by Build if 1s are very few.
+func (builder *BitVectorBuilderData) BuildSparse() (SuccinctBitVector, error) {
+	vec := newSparseVector(builder.vec)
+	builder.vec = new(BitVectorData)
+	return vec, nil
+}
+
+// ErrorInvalidCharacter indicates that a string contains character other than `0` and `1`.
+var ErrorInvalidCharacter = errors.New("Invalid character in bit string")
+
+// NewVectorFromWords returns new succinct bit vector that holds the first `size` bits of `words`.
+// The i-th bit is the (i % 64)-th bit of `words[i / 64]`.
+func NewVectorFromWords(words []uint64, size uint64) (SuccinctBitVector, error) {
+	var blockNum = (size + sBlockSize - 1) / sBlockSize
+	if uint64(len(words)) < blockNum {
+		return nil, ErrorOutOfRange
+	}
+	blocks := make([]uint64, blockNum)
+	copy(blocks, words)
+	if r := size % sBlockSize; r != 0 {
+		blocks[blockNum-1] = mask(blocks[blockNum-1], r)
+	}
+	return newVectorFromBlocks(blocks, size), nil
+}
+
+// NewVectorFromBools returns new succinct bit vector whose i-th bit is `bits[i]`.
+func NewVectorFromBools(bits []bool) (SuccinctBitVector, error) {
+	var size = uint64(len(bits))
+	blocks := make([]uint64, (size+sBlockSize-1)/sBlockSize)
+	for i, b := range bits {
+		if b {
+			blocks[uint64(i)/sBlockSize] |= 1 << (uint64(i) % sBlockSize)
+		}
+	}
+	return newVectorFromBlocks(blocks, size), nil
+}
+
+// NewVectorFromPositions returns new succinct bit vector of `size` bits whose 1s are at `positions`.
+// `positions` must be sorted in ascending order.
+func NewVectorFromPositions(positions []uint64, size uint64) (SuccinctBitVector, error) {
+	blocks := make([]uint64, (size+sBlockSize-1)/sBlockSize)
+	for i, pos := range positions {
+		if pos >= size {
+			return nil, ErrorOutOfRange
+		}
+		if i > 0 && pos < positions[i-1] {
+			return nil, ErrorNotSorted
+		}
+		blocks[pos/sBlockSize] |= 1 << (pos % sBlockSize)
+	}
+	return newVectorFromBlocks(blocks, size), nil
+}
+
+// NewVectorFromString returns new succinct bit vector whose i-th bit is the i-th character of `s`.
+// `s` must consist of `0` and `1`.
+func NewVectorFromString(s string) (SuccinctBitVector, error) {
+	var size = uint64(len(s))
+	blocks := make([]uint64, (size+sBlockSize-1)/sBlockSize)
+	for i := uint64(0); i < size; i++ {
+		switch s[i] {
+		case '0':
+		case '1':
+			blocks[i/sBlockSize] |= 1 << (i % sBlockSize)
+		default:
+			return nil, ErrorInvalidCharacter
+		}
+	}
+	return newVectorFromBlocks(blocks, size), nil
+}
+
+// Concat returns new succinct bit vector that holds bits of `vectors` in order.
+func Concat(vectors ...SuccinctBitVector) (SuccinctBitVector, error) {
+	vec := new(BitVectorData)
+	for _, v := range vectors {
+		if err := vec.appendVector(v); err != nil {
+			return nil, err
+		}
+	}
+	vec.build(true, true)
+	return vec, nil
+}
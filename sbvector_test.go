@@ -1,7 +1,9 @@
 package sbvector
 
 import (
+	"bytes"
 	"encoding/binary"
+	"math/rand"
 	"testing"
 )
 
@@ -438,7 +440,7 @@ func TestMarshal(t *testing.T) {
 	builder = NewVectorBuilderWithInit(vec3)
 	builder.PushBack(true)
 	vec3, err = builder.Build(true, true)
-	buffer, err = vec3.MarshalBinary()
+	buffer = legacyBinary(vec3.(*BitVectorData))
 	badBuf := make([]byte, len(buffer))
 	copy(badBuf, buffer)
 	badBuf[24] = 0xFF
@@ -467,3 +469,275 @@ func TestMarshal(t *testing.T) {
 		t.Error()
 	}
 }
+
+func TestNextPrev(t *testing.T) {
+	builder := NewVectorBuilder()
+
+	for _, v := range bitCases {
+		builder.Set(v.pos, v.bit)
+	}
+	builder.PushBack(false)
+
+	vec, _ := builder.Build(true, true)
+
+	var bits []bool
+	for i := uint64(0); i < vec.Size(); i++ {
+		b, _ := vec.Get(i)
+		bits = append(bits, b)
+	}
+
+	for i := uint64(0); i < vec.Size(); i++ {
+		for _, b := range []bool{true, false} {
+			var next = NotFound
+			for j := i; j < vec.Size(); j++ {
+				if bits[j] == b {
+					next = j
+					break
+				}
+			}
+			var prev = NotFound
+			for j := int64(i); j >= 0; j-- {
+				if bits[j] == b {
+					prev = uint64(j)
+					break
+				}
+			}
+
+			var pos uint64
+			var err error
+			if b {
+				pos, err = vec.NextOne(i)
+			} else {
+				pos, err = vec.NextZero(i)
+			}
+			if err != nil || pos != next {
+				t.Fatal("Next", i, b, "Expected", next, "got", pos)
+			}
+			if b {
+				pos, err = vec.PrevOne(i)
+			} else {
+				pos, err = vec.PrevZero(i)
+			}
+			if err != nil || pos != prev {
+				t.Fatal("Prev", i, b, "Expected", prev, "got", pos)
+			}
+		}
+	}
+
+	pos, err := vec.NextOne(vec.Size())
+	if err != nil || pos != NotFound {
+		t.Error()
+	}
+	pos, err = vec.NextZero(vec.Size() + 1)
+	if err != ErrorOutOfRange || pos != NotFound {
+		t.Error()
+	}
+	pos, err = vec.PrevOne(vec.Size())
+	if err != ErrorOutOfRange || pos != NotFound {
+		t.Error()
+	}
+}
+
+func TestRankAtEnd(t *testing.T) {
+	builder := NewVectorBuilder()
+	builder.PushBackBits(0xFFFFFFFFFFFFFFFF, 64)
+	builder.PushBackBits(0, 64)
+	vec, _ := builder.Build(false, false)
+
+	rank, err := vec.Rank1(128)
+	if err != nil || rank != 64 {
+		t.Error("Expected", 64, "got", rank)
+	}
+	rank, err = vec.Rank0(128)
+	if err != nil || rank != 64 {
+		t.Error("Expected", 64, "got", rank)
+	}
+}
+
+// legacyBinary returns the binary image in the layout used before the header was introduced.
+func legacyBinary(vec *BitVectorData) []byte {
+	buffer := new(bytes.Buffer)
+	var serializedSize = uint64(len(vec.blocks)+len(vec.select1Table)+len(vec.select0Table))*sizeOfInt64 +
+		uint64(len(vec.ranks))*uint64(binarySize) + sizeOfInt64*3 + sizeOfInt32*4
+	binary.Write(buffer, binary.LittleEndian, serializedSize)
+	binary.Write(buffer, binary.LittleEndian, vec.size)
+	binary.Write(buffer, binary.LittleEndian, vec.numOf1s)
+	binary.Write(buffer, binary.LittleEndian, uint32(len(vec.blocks)))
+	binary.Write(buffer, binary.LittleEndian, vec.blocks)
+	binary.Write(buffer, binary.LittleEndian, uint32(len(vec.ranks)))
+	for _, ri := range vec.ranks {
+		buf, _ := ri.MarshalBinary()
+		buffer.Write(buf)
+	}
+	binary.Write(buffer, binary.LittleEndian, uint32(len(vec.select1Table)))
+	binary.Write(buffer, binary.LittleEndian, vec.select1Table)
+	binary.Write(buffer, binary.LittleEndian, uint32(len(vec.select0Table)))
+	binary.Write(buffer, binary.LittleEndian, vec.select0Table)
+	return buffer.Bytes()
+}
+
+func TestRankRange(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, size := range []uint64{0, 64, 1000, 2048, 5000} {
+		builder := NewVectorBuilder()
+		for i := uint64(0); i < size; i++ {
+			builder.PushBack(r.Intn(3) == 0)
+		}
+		vec, _ := builder.Build(false, false)
+		bv := vec.(*BitVectorData)
+		for k := 0; k < 2000; k++ {
+			var l = uint64(r.Int63n(int64(size) + 1))
+			var e = l + uint64(r.Int63n(int64(size-l)+1))
+			if k%4 == 0 && e-l > 100 {
+				e = l + uint64(r.Intn(100))
+			}
+			r1, _ := vec.Rank1(l)
+			r2, _ := vec.Rank1(e)
+			if count, err := bv.PopcountRange(l, e); err != nil || count != r2-r1 {
+				t.Fatal("PopcountRange", l, e, "Expected", r2-r1, "got", count)
+			}
+			if count, err := bv.RankRange(l, e, false); err != nil || count != (e-l)-(r2-r1) {
+				t.Fatal("RankRange", l, e, "Expected", (e-l)-(r2-r1), "got", count)
+			}
+		}
+		if count, err := bv.RankRange(0, size, true); err != nil || count != vec.NumOfBits(true) {
+			t.Error("Expected", vec.NumOfBits(true), "got", count)
+		}
+		if count, err := bv.PopcountRange(size, size); err != nil || count != 0 {
+			t.Error("Expected", 0, "got", count)
+		}
+		if _, err := bv.PopcountRange(0, size+1); err != ErrorOutOfRange {
+			t.Error("Expected", ErrorOutOfRange, "got", err)
+		}
+		if size > 0 {
+			if _, err := bv.RankRange(size, size-1, true); err != ErrorOutOfRange {
+				t.Error("Expected", ErrorOutOfRange, "got", err)
+			}
+		}
+	}
+}
+
+func TestNewVectorFrom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, size := range []int{0, 1, 64, 1000} {
+		builder := NewVectorBuilder()
+		var bools = make([]bool, size)
+		var words = make([]uint64, (size+63)/64+1)
+		var positions []uint64
+		var str []byte
+		for i := range bools {
+			bools[i] = r.Intn(3) == 0
+			builder.PushBack(bools[i])
+			str = append(str, '0')
+			if bools[i] {
+				words[i/64] |= 1 << uint(i%64)
+				positions = append(positions, uint64(i))
+				str[i] = '1'
+			}
+		}
+		// Bits after `size` are ignored.
+		words[len(words)-1] = 0xFFFFFFFFFFFFFFFF
+		if size%64 != 0 {
+			words[len(words)-2] |= 1 << 63
+		}
+		expected, _ := builder.Build(true, true)
+
+		vec, err := NewVectorFromWords(words, uint64(size))
+		if err != nil {
+			t.Fatal(err)
+		}
+		compareVectors(t, expected, vec)
+		vec, err = NewVectorFromBools(bools)
+		if err != nil {
+			t.Fatal(err)
+		}
+		compareVectors(t, expected, vec)
+		vec, err = NewVectorFromPositions(positions, uint64(size))
+		if err != nil {
+			t.Fatal(err)
+		}
+		compareVectors(t, expected, vec)
+		vec, err = NewVectorFromString(string(str))
+		if err != nil {
+			t.Fatal(err)
+		}
+		compareVectors(t, expected, vec)
+	}
+
+	if _, err := NewVectorFromWords([]uint64{1}, 65); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+	if _, err := NewVectorFromPositions([]uint64{3, 1}, 10); err != ErrorNotSorted {
+		t.Error("Expected", ErrorNotSorted, "got", err)
+	}
+	if _, err := NewVectorFromPositions([]uint64{10}, 10); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+	if _, err := NewVectorFromString("0102"); err != ErrorInvalidCharacter {
+		t.Error("Expected", ErrorInvalidCharacter, "got", err)
+	}
+}
+
+func TestConcat(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	expectedBuilder := NewVectorBuilder()
+	appendBuilder := NewVectorBuilder()
+	var vectors []SuccinctBitVector
+	for k, size := range []int{0, 1, 63, 64, 65, 1000, 3, 700} {
+		builder := NewVectorBuilder()
+		for i := 0; i < size; i++ {
+			b := r.Intn(3) == 0
+			builder.PushBack(b)
+			expectedBuilder.PushBack(b)
+		}
+		var vec SuccinctBitVector
+		switch k % 3 {
+		case 0:
+			vec, _ = builder.Build(false, false)
+		case 1:
+			vec, _ = builder.BuildRRR()
+		default:
+			vec, _ = builder.BuildSparse()
+		}
+		vectors = append(vectors, vec)
+		if err := appendBuilder.AppendVector(vec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, _ := expectedBuilder.Build(true, true)
+	vec, err := Concat(vectors...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareVectors(t, expected, vec)
+	vec, _ = appendBuilder.Build(true, true)
+	compareVectors(t, expected, vec)
+
+	vec, err = Concat()
+	if err != nil || vec.Size() != 0 {
+		t.Error("Expected", 0, "got", vec.Size())
+	}
+}
+
+func TestBuilderWithInit(t *testing.T) {
+	vec, _ := NewVectorFromString("0011100111")
+	rrr, _ := NewVectorBuilderWithInit(vec).BuildRRR()
+	for _, src := range []SuccinctBitVector{vec, rrr} {
+		builder := NewVectorBuilderWithInit(src)
+		builder.Set(0, true)
+		builder.PushBack(true)
+		vec2, _ := builder.Build(true, true)
+		if n := vec2.Size(); n != 11 {
+			t.Error("Expected", 11, "got", n)
+		}
+		if n := vec2.NumOfBits(true); n != 8 {
+			t.Error("Expected", 8, "got", n)
+		}
+		if b, _ := src.Get(0); b {
+			t.Error("Expected", false, "got", b)
+		}
+	}
+	if n := NewVectorBuilderWithInit(nil).Size(); n != 0 {
+		t.Error("Expected", 0, "got", n)
+	}
+}
@@ -0,0 +1,198 @@
+package sbvector
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// SparseVectorData holds information about sparse bit vector.
+// It stores only the positions of 1s by Elias-Fano encoding,
+// so that it takes less space than BitVectorData if the number of 1s is much smaller than the size.
+type SparseVectorData struct {
+	ef   EliasFano
+	size uint64
+}
+
+// newSparseVector returns new sparse bit vector that has same bits as `src`.
+func newSparseVector(src *BitVectorData) *SparseVectorData {
+	return newSparseVectorFromPositions(positionsOf1s(src), src.size)
+}
+
+// newSparseVectorFromPositions returns new sparse bit vector of `size` bits whose 1s are at `positions`.
+// `positions` must be sorted and unique, and each position must be less than `size`.
+func newSparseVectorFromPositions(positions []uint64, size uint64) *SparseVectorData {
+	vec := new(SparseVectorData)
+	vec.size = size
+	vec.ef = *newEliasFano(positions, size)
+	return vec
+}
+
+// positionsOf1s returns positions of all 1s in `vec`.
+func positionsOf1s(vec *BitVectorData) []uint64 {
+	var positions []uint64
+	for i, x := range vec.blocks {
+		for x != 0 {
+			var pos = uint64(i)*sBlockSize + uint64(countTrailingZeros(x))
+			if pos >= vec.size {
+				break
+			}
+			positions = append(positions, pos)
+			x &= x - 1
+		}
+	}
+	return positions
+}
+
+// Get returns value from bit vector by index.
+func (vec *SparseVectorData) Get(i uint64) (bool, error) {
+	if i >= vec.size {
+		return false, ErrorOutOfRange
+	}
+	var rank = vec.ef.lowerBound(i)
+	return rank < vec.ef.length && vec.ef.access(rank) == i, nil
+}
+
+// GetBits returns bits from bit vector.
+func (vec *SparseVectorData) GetBits(pos uint64, length uint64) (uint64, error) {
+	if (pos + length) > vec.size {
+		return NotFound, ErrorOutOfRange
+	}
+	var result uint64
+	for rank := vec.ef.lowerBound(pos); rank < vec.ef.length; rank++ {
+		var x = vec.ef.access(rank)
+		if x >= pos+length {
+			break
+		}
+		result |= 1 << (x - pos)
+	}
+	return result, nil
+}
+
+// Rank1 returns number of the bits equal to `1` up to positin `i`
+func (vec *SparseVectorData) Rank1(i uint64) (uint64, error) {
+	if i > vec.size {
+		return NotFound, ErrorOutOfRange
+	}
+	return vec.ef.lowerBound(i), nil
+}
+
+// Rank0 returns number of the bits equal to `0` up to positin `i`
+func (vec *SparseVectorData) Rank0(i uint64) (uint64, error) {
+	rank, err := vec.Rank1(i)
+	if err != nil {
+		return rank, err
+	}
+	return i - rank, nil
+}
+
+// Rank returns number of the bits equal to `b` up to position `i`
+func (vec *SparseVectorData) Rank(i uint64, b bool) (uint64, error) {
+	if b {
+		return vec.Rank1(i)
+	}
+	return vec.Rank0(i)
+}
+
+// Select1 returns the position of the x-th occurence of 1
+func (vec *SparseVectorData) Select1(x uint64) (uint64, error) {
+	if vec.ef.length <= x {
+		return NotFound, ErrorOutOfRange
+	}
+	return vec.ef.access(x), nil
+}
+
+// Select0 returns the position of the x-th occurence of 0
+func (vec *SparseVectorData) Select0(x uint64) (uint64, error) {
+	if vec.NumOfBits(false) <= x {
+		return NotFound, ErrorOutOfRange
+	}
+	// Find number of 1s before the x-th 0, that is the first 1 preceded by more than x 0s.
+	var begin uint64
+	var end = vec.ef.length
+	for begin < end {
+		var pivot = (begin + end) / 2
+		if vec.ef.access(pivot)-pivot > x {
+			end = pivot
+		} else {
+			begin = pivot + 1
+		}
+	}
+	return x + begin, nil
+}
+
+// Select returns the position of the x-th occurrence of `b`
+func (vec *SparseVectorData) Select(x uint64, b bool) (uint64, error) {
+	if b {
+		return vec.Select1(x)
+	}
+	return vec.Select0(x)
+}
+
+// NextOne returns the position of the first 1 at or after position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *SparseVectorData) NextOne(i uint64) (uint64, error) {
+	return nextOf(vec, i, true)
+}
+
+// NextZero returns the position of the first 0 at or after position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *SparseVectorData) NextZero(i uint64) (uint64, error) {
+	return nextOf(vec, i, false)
+}
+
+// PrevOne returns the position of the last 1 at or before position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *SparseVectorData) PrevOne(i uint64) (uint64, error) {
+	return prevOf(vec, i, true)
+}
+
+// PrevZero returns the position of the last 0 at or before position `i`.
+// It returns NotFound if there is no such bit.
+func (vec *SparseVectorData) PrevZero(i uint64) (uint64, error) {
+	return prevOf(vec, i, false)
+}
+
+// Size returns size of bit vector
+func (vec *SparseVectorData) Size() uint64 {
+	return vec.size
+}
+
+// NumOfBits returns number of bits that matches with argument in the bit vector.
+func (vec *SparseVectorData) NumOfBits(b bool) uint64 {
+	if b {
+		return vec.ef.length
+	}
+	return vec.size - vec.ef.length
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (vec *SparseVectorData) MarshalBinary() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	binary.Write(buffer, binary.LittleEndian, &vec.size)
+	buf, err := vec.ef.marshalPayload()
+	if err != nil {
+		return nil, err
+	}
+	buffer.Write(buf)
+	return marshalContainer(kindSparse, 0, buffer.Bytes()), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (vec *SparseVectorData) UnmarshalBinary(data []byte) error {
+	_, payload, err := unmarshalContainer(data, kindSparse, true)
+	if err != nil {
+		return err
+	}
+	if uint64(len(payload)) < sizeOfInt64 {
+		return ErrorInvalidFormat
+	}
+	vec.size = binary.LittleEndian.Uint64(payload)
+	buf, err := vec.ef.unmarshal(payload[sizeOfInt64:])
+	if err != nil {
+		return err
+	}
+	if len(buf) != 0 || vec.ef.upper.size != vec.ef.length+(vec.size>>vec.ef.lowWidth)+1 {
+		return ErrorInvalidFormat
+	}
+	return nil
+}
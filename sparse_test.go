@@ -0,0 +1,93 @@
+package sbvector
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSparseVector(t *testing.T) {
+	builder := NewVectorBuilder()
+
+	for _, v := range bitCases {
+		builder.Set(v.pos, v.bit)
+	}
+
+	vec, err := builder.BuildSparse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range bitCases {
+		x, err := vec.Get(v.pos)
+		if err != nil || x != v.bit {
+			t.Error("Expected", v.bit, "got", x)
+		}
+	}
+
+	for _, v := range rankCases {
+		rank, err := vec.Rank1(v.pos)
+		if err != nil || rank != v.rank {
+			t.Error("Expected", v.rank, "got", rank)
+		}
+	}
+
+	for _, v := range select1Cases {
+		pos, err := vec.Select1(v.index)
+		if err != nil || pos != v.pos {
+			t.Error("Expected", v.pos, "got", pos)
+		}
+	}
+
+	for _, v := range select0Cases {
+		pos, err := vec.Select0(v.index)
+		if err != nil || pos != v.pos {
+			t.Error("Expected", v.pos, "got", pos)
+		}
+	}
+
+	if _, err := vec.Get(6001); err != ErrorOutOfRange {
+		t.Error()
+	}
+	if rank, err := vec.Rank1(6002); err != ErrorOutOfRange || rank != NotFound {
+		t.Error()
+	}
+	if pos, err := vec.Select1(20); err != ErrorOutOfRange || pos != NotFound {
+		t.Error()
+	}
+	if pos, err := vec.Select0(5981); err != ErrorOutOfRange || pos != NotFound {
+		t.Error()
+	}
+}
+
+func TestSparseVectorRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, density := range []int{0, 1, 30, 100} {
+		builder := NewVectorBuilder()
+		sparseBuilder := NewVectorBuilder()
+		for i := 0; i < 10000; i++ {
+			b := r.Intn(100) < density
+			builder.PushBack(b)
+			sparseBuilder.PushBack(b)
+		}
+		expected, _ := builder.Build(true, true)
+		vec, _ := sparseBuilder.BuildSparse()
+		compareVectors(t, expected, vec)
+
+		buf, err := vec.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		vec2, err := NewVectorFromBinary(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := vec2.(*SparseVectorData); !ok {
+			t.Fatal("Expected *SparseVectorData")
+		}
+		compareVectors(t, expected, vec2)
+
+		if err := vec2.UnmarshalBinary(buf[:len(buf)-1]); err == nil {
+			t.Error("Expected error")
+		}
+	}
+}
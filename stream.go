@@ -0,0 +1,303 @@
+package sbvector
+
+import (
+	"encoding/binary"
+	"hash/crc64"
+	"io"
+	"unsafe"
+)
+
+const streamBufferSize = 4096
+
+// WriteTo implements the io.WriterTo interface.
+// It writes the same binary image as MarshalBinary without building the whole image in memory.
+func (vec *BitVectorData) WriteTo(w io.Writer) (int64, error) {
+	bw := newBinaryWriter(w)
+	bw.write(encodeHeader(formatHeader{kindBitVector, vec.indexFlags(), vec.payloadSize()}))
+	bw.writeUint64(vec.size)
+	bw.writeUint64(vec.numOf1s)
+	bw.writeUint64(uint64(len(vec.blocks)))
+	bw.writeUint64(uint64(len(vec.ranks)))
+	bw.writeUint64(uint64(len(vec.select1Table)))
+	bw.writeUint64(uint64(len(vec.select0Table)))
+
+	bw.writeUint64s(vec.blocks)
+	for _, ri := range vec.ranks {
+		bw.writeUint64(ri.absVal)
+		bw.writeUint64(ri.rel)
+	}
+	bw.writeUint64s(vec.select1Table)
+	bw.writeUint64s(vec.select0Table)
+	bw.writeChecksum()
+	return bw.flush()
+}
+
+// ReadFrom implements the io.ReaderFrom interface.
+// It reads the binary image written by MarshalBinary or WriteTo, and reads nothing after the image.
+func (vec *BitVectorData) ReadFrom(r io.Reader) (int64, error) {
+	br := newBinaryReader(r)
+	head := br.read(int(sizeOfInt64))
+	if br.err != nil {
+		return br.n, br.err
+	}
+	if hasMagic(head) {
+		return vec.readPayload(br)
+	}
+	var dataSize = binary.LittleEndian.Uint64(head)
+	if !isLegacySize(dataSize) {
+		return br.n, ErrorBadMagic
+	}
+	return vec.readLegacy(br, dataSize)
+}
+
+// readPayload reads the image with the header. The magic number has been read already.
+func (vec *BitVectorData) readPayload(br *binaryReader) (int64, error) {
+	var headerBuf = make([]byte, headerSize)
+	copy(headerBuf, formatMagic)
+	copy(headerBuf[len(formatMagic):], br.read(int(headerSize)-len(formatMagic)))
+	if br.err != nil {
+		return br.n, br.err
+	}
+	header, err := decodeHeader(headerBuf)
+	if err != nil {
+		return br.n, err
+	}
+	if header.kind != kindBitVector || header.payloadSize < bitVectorFieldsSize || header.payloadSize >= maxImageSize {
+		return br.n, ErrorInvalidFormat
+	}
+
+	size := br.readUint64()
+	numOf1s := br.readUint64()
+	blockNum := br.readUint64()
+	rankTableSize := br.readUint64()
+	select1TableSize := br.readUint64()
+	select0TableSize := br.readUint64()
+	if br.err != nil {
+		return br.n, br.err
+	}
+	var payloadSize = header.payloadSize
+	if blockNum > payloadSize || rankTableSize > payloadSize || select1TableSize > payloadSize || select0TableSize > payloadSize ||
+		bitVectorFieldsSize+(blockNum+select1TableSize+select0TableSize)*sizeOfInt64+rankTableSize*uint64(binarySize) != payloadSize ||
+		size > blockNum*sBlockSize || numOf1s > size {
+		return br.n, ErrorInvalidFormat
+	}
+
+	blocks := br.readUint64s(blockNum)
+	ranks := br.readRankIndexes(rankTableSize)
+	select1Table := br.readUint64s(select1TableSize)
+	select0Table := br.readUint64s(select0TableSize)
+	var expected = br.crc
+	checksum := br.readUint64()
+	if br.err != nil {
+		return br.n, br.err
+	}
+	if checksum != expected {
+		return br.n, ErrorChecksumMismatch
+	}
+
+	vec.size = size
+	vec.numOf1s = numOf1s
+	vec.blocks = blocks
+	vec.ranks = ranks
+	vec.select1Table = select1Table
+	vec.select0Table = select0Table
+	if header.flags != vec.indexFlags() {
+		return br.n, ErrorInvalidFormat
+	}
+	return br.n, nil
+}
+
+// readLegacy reads the image without the header. The size at the head of the image has been read already.
+func (vec *BitVectorData) readLegacy(br *binaryReader, dataSize uint64) (int64, error) {
+	size := br.readUint64()
+	numOf1s := br.readUint64()
+	if br.err != nil {
+		return br.n, br.err
+	}
+	if dataSize < minimumSize {
+		return br.n, ErrorInvalidLength
+	}
+
+	blockNum := br.readUint32()
+	if br.err != nil || uint64(br.n)+uint64(blockNum)*sizeOfInt64 > dataSize {
+		return br.n, br.errorOr(ErrorInvalidFormat)
+	}
+	blocks := br.readUint64s(uint64(blockNum))
+
+	var tmpRankIndex rankIndex
+	sizeOfRankIndex := uint64(unsafe.Sizeof(tmpRankIndex))
+	rankTableSize := br.readUint32()
+	if br.err != nil || uint64(br.n)+uint64(rankTableSize)*sizeOfRankIndex > dataSize {
+		return br.n, br.errorOr(ErrorInvalidFormat)
+	}
+	ranks := br.readRankIndexes(uint64(rankTableSize))
+
+	select1TableSize := br.readUint32()
+	if br.err != nil || uint64(br.n)+uint64(select1TableSize)*sizeOfInt64 > dataSize {
+		return br.n, br.errorOr(ErrorInvalidFormat)
+	}
+	select1Table := br.readUint64s(uint64(select1TableSize))
+
+	select0TableSize := br.readUint32()
+	if br.err != nil || uint64(br.n)+uint64(select0TableSize)*sizeOfInt64 != dataSize {
+		return br.n, br.errorOr(ErrorInvalidFormat)
+	}
+	select0Table := br.readUint64s(uint64(select0TableSize))
+	if br.err != nil {
+		return br.n, br.err
+	}
+
+	vec.size = size
+	vec.numOf1s = numOf1s
+	vec.blocks = blocks
+	vec.ranks = ranks
+	vec.select1Table = select1Table
+	vec.select0Table = select0Table
+	return br.n, nil
+}
+
+// binaryWriter writes little endian values to io.Writer through a buffer.
+type binaryWriter struct {
+	w   io.Writer
+	buf []byte
+	n   int64
+	crc uint64
+	err error
+}
+
+func newBinaryWriter(w io.Writer) *binaryWriter {
+	return &binaryWriter{w: w, buf: make([]byte, 0, streamBufferSize)}
+}
+
+func (bw *binaryWriter) reserve(size int) {
+	if len(bw.buf)+size > cap(bw.buf) {
+		bw.flush()
+	}
+}
+
+func (bw *binaryWriter) write(p []byte) {
+	bw.reserve(len(p))
+	bw.buf = append(bw.buf, p...)
+}
+
+func (bw *binaryWriter) writeUint64(x uint64) {
+	bw.reserve(int(sizeOfInt64))
+	var tmp [8]byte
+	binary.LittleEndian.PutUint64(tmp[:], x)
+	bw.buf = append(bw.buf, tmp[:]...)
+}
+
+func (bw *binaryWriter) writeUint64s(s []uint64) {
+	for _, x := range s {
+		bw.writeUint64(x)
+	}
+}
+
+// writeChecksum writes checksum of the data written so far.
+func (bw *binaryWriter) writeChecksum() {
+	bw.writeUint64(crc64.Update(bw.crc, crcTable, bw.buf))
+}
+
+// flush writes buffered data, and returns number of bytes written and the first error.
+func (bw *binaryWriter) flush() (int64, error) {
+	bw.crc = crc64.Update(bw.crc, crcTable, bw.buf)
+	if bw.err == nil && len(bw.buf) > 0 {
+		var n int
+		n, bw.err = bw.w.Write(bw.buf)
+		bw.n += int64(n)
+	}
+	bw.buf = bw.buf[:0]
+	return bw.n, bw.err
+}
+
+// binaryReader reads little endian values from io.Reader.
+// It reads no more bytes than requested, so that the reader can be shared with other decoders.
+type binaryReader struct {
+	r   io.Reader
+	buf []byte
+	n   int64
+	crc uint64
+	err error
+}
+
+func newBinaryReader(r io.Reader) *binaryReader {
+	return &binaryReader{r: r, buf: make([]byte, streamBufferSize)}
+}
+
+func (br *binaryReader) read(size int) []byte {
+	if br.err != nil {
+		return nil
+	}
+	n, err := io.ReadFull(br.r, br.buf[:size])
+	if err == io.EOF && br.n > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	br.n += int64(n)
+	br.crc = crc64.Update(br.crc, crcTable, br.buf[:n])
+	br.err = err
+	if err != nil {
+		return nil
+	}
+	return br.buf[:size]
+}
+
+func (br *binaryReader) readUint32() uint32 {
+	buf := br.read(int(sizeOfInt32))
+	if buf == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(buf)
+}
+
+func (br *binaryReader) readUint64() uint64 {
+	buf := br.read(int(sizeOfInt64))
+	if buf == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(buf)
+}
+
+// readUint64s reads `n` values. The slice grows as the values are read,
+// so that a corrupted count does not allocate more memory than the stream holds.
+func (br *binaryReader) readUint64s(n uint64) []uint64 {
+	var chunk = uint64(len(br.buf)) / sizeOfInt64
+	if n < chunk {
+		chunk = n
+	}
+	s := make([]uint64, 0, chunk)
+	for uint64(len(s)) < n {
+		if rest := n - uint64(len(s)); rest < chunk {
+			chunk = rest
+		}
+		buf := br.read(int(chunk * sizeOfInt64))
+		if buf == nil {
+			return nil
+		}
+		for i := uint64(0); i < chunk; i++ {
+			s = append(s, binary.LittleEndian.Uint64(buf[i*sizeOfInt64:]))
+		}
+	}
+	return s
+}
+
+// readRankIndexes reads `n` rank indexes.
+func (br *binaryReader) readRankIndexes(n uint64) []rankIndex {
+	s := br.readUint64s(2 * n)
+	if s == nil {
+		return nil
+	}
+	ranks := make([]rankIndex, n)
+	for i := range ranks {
+		ranks[i].absVal = s[2*i]
+		ranks[i].rel = s[2*i+1]
+	}
+	return ranks
+}
+
+// errorOr returns the read error if exists, otherwise returns `err`.
+func (br *binaryReader) errorOr(err error) error {
+	if br.err != nil {
+		return br.err
+	}
+	return err
+}
@@ -0,0 +1,130 @@
+package sbvector
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+type failingWriter struct {
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		n := w.limit
+		w.limit = 0
+		return n, errors.New("write failed")
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestStream(t *testing.T) {
+	builder := NewVectorBuilder()
+	for i := uint64(0); i < 100000; i++ {
+		builder.PushBack(i%3 == 0)
+	}
+	vec, _ := builder.Build(true, true)
+	bv := vec.(*BitVectorData)
+
+	for _, v := range bitCases {
+		builder.Set(v.pos, v.bit)
+	}
+	vec2, _ := builder.Build(false, true)
+
+	expected, _ := vec.MarshalBinary()
+	var buffer bytes.Buffer
+	n, err := bv.WriteTo(&buffer)
+	if err != nil || n != int64(len(expected)) {
+		t.Fatal("Expected", len(expected), "got", n, err)
+	}
+	if !bytes.Equal(buffer.Bytes(), expected) {
+		t.Fatal("WriteTo and MarshalBinary differ")
+	}
+	expected2, _ := vec2.MarshalBinary()
+	vec2.(*BitVectorData).WriteTo(&buffer)
+
+	reader := bytes.NewReader(buffer.Bytes())
+	vec3 := new(BitVectorData)
+	n, err = vec3.ReadFrom(reader)
+	if err != nil || n != int64(len(expected)) {
+		t.Fatal("Expected", len(expected), "got", n, err)
+	}
+	compareVectors(t, vec, vec3)
+
+	vec4 := new(BitVectorData)
+	n, err = vec4.ReadFrom(reader)
+	if err != nil || n != int64(len(expected2)) {
+		t.Fatal("Expected", len(expected2), "got", n, err)
+	}
+	compareVectors(t, vec2, vec4)
+
+	if _, err := vec4.ReadFrom(reader); err != io.EOF {
+		t.Error("Expected", io.EOF, "got", err)
+	}
+
+	for _, size := range []int{7, 30, 100, len(expected) - 1} {
+		if _, err := new(BitVectorData).ReadFrom(bytes.NewReader(expected[:size])); err != io.ErrUnexpectedEOF {
+			t.Error("Expected", io.ErrUnexpectedEOF, "got", err)
+		}
+	}
+
+	badBuf := make([]byte, len(expected))
+	copy(badBuf, expected)
+	badBuf[24] = 0xFF
+	if _, err := new(BitVectorData).ReadFrom(bytes.NewReader(badBuf)); err != ErrorInvalidFormat {
+		t.Error("Expected", ErrorInvalidFormat, "got", err)
+	}
+
+	copy(badBuf, expected)
+	badBuf[100] ^= 0x01
+	if _, err := new(BitVectorData).ReadFrom(bytes.NewReader(badBuf)); err != ErrorChecksumMismatch {
+		t.Error("Expected", ErrorChecksumMismatch, "got", err)
+	}
+
+	legacy := legacyBinary(bv)
+	vec5 := new(BitVectorData)
+	n, err = vec5.ReadFrom(bytes.NewReader(legacy))
+	if err != nil || n != int64(len(legacy)) {
+		t.Fatal("Expected", len(legacy), "got", n, err)
+	}
+	compareVectors(t, vec, vec5)
+
+	if _, err := new(BitVectorData).ReadFrom(bytes.NewReader([]byte("NOTAVECTOR"))); err != ErrorBadMagic {
+		t.Error("Expected", ErrorBadMagic, "got", err)
+	}
+
+	if _, err := bv.WriteTo(&failingWriter{limit: 10000}); err == nil {
+		t.Error("Expected error")
+	}
+}
+
+func TestStreamCorruptedCount(t *testing.T) {
+	for _, payloadSize := range []uint64{1 << 62, 1 << 40} {
+		var buffer bytes.Buffer
+		buffer.Write(encodeHeader(formatHeader{kindBitVector, 0, payloadSize}))
+		var blockNum = (payloadSize - bitVectorFieldsSize) / sizeOfInt64
+		for _, x := range []uint64{blockNum * sBlockSize, 0, blockNum, 0, 0, 0} {
+			binary.Write(&buffer, binary.LittleEndian, x)
+		}
+		var expected error = io.ErrUnexpectedEOF
+		if payloadSize >= maxImageSize {
+			expected = ErrorInvalidFormat
+		}
+		if _, err := new(BitVectorData).ReadFrom(&buffer); err != expected {
+			t.Error("Expected", expected, "got", err)
+		}
+	}
+
+	var buffer bytes.Buffer
+	for _, x := range []uint64{1 << 47, 1 << 40, 0} {
+		binary.Write(&buffer, binary.LittleEndian, x)
+	}
+	binary.Write(&buffer, binary.LittleEndian, uint32(1<<31))
+	if _, err := new(BitVectorData).ReadFrom(&buffer); err != io.ErrUnexpectedEOF {
+		t.Error("Expected", io.ErrUnexpectedEOF, "got", err)
+	}
+}
@@ -0,0 +1,241 @@
+package sbvector
+
+// SliceView is read only view of range [begin, begin+size) of BitVectorData.
+// Queries are translated onto the blocks and indexes of the parent without copying.
+type SliceView struct {
+	parent  *BitVectorData
+	begin   uint64
+	size    uint64
+	rank    uint64
+	numOf1s uint64
+}
+
+// ComplementView is read only view of BitVectorData whose bits are inverted.
+type ComplementView struct {
+	parent *BitVectorData
+}
+
+// Slice returns view of range [l, r) of the bit vector.
+func (vec *BitVectorData) Slice(l uint64, r uint64) (*SliceView, error) {
+	if l > r || r > vec.size {
+		return nil, ErrorOutOfRange
+	}
+	view := &SliceView{parent: vec, begin: l, size: r - l}
+	view.rank, _ = vec.Rank1(l)
+	view.numOf1s, _ = vec.PopcountRange(l, r)
+	return view, nil
+}
+
+// Complement returns view of the bit vector whose bits are inverted.
+func (vec *BitVectorData) Complement() *ComplementView {
+	return &ComplementView{vec}
+}
+
+// Get returns value from bit vector by index.
+func (view *SliceView) Get(i uint64) (bool, error) {
+	if i >= view.size {
+		return false, ErrorOutOfRange
+	}
+	return view.parent.Get(view.begin + i)
+}
+
+// GetBits returns bits from bit vector.
+func (view *SliceView) GetBits(pos uint64, length uint64) (uint64, error) {
+	if (pos + length) > view.size {
+		return NotFound, ErrorOutOfRange
+	}
+	return view.parent.GetBits(view.begin+pos, length)
+}
+
+// Rank1 returns number of the bits equal to `1` up to positin `i`
+func (view *SliceView) Rank1(i uint64) (uint64, error) {
+	if i > view.size {
+		return NotFound, ErrorOutOfRange
+	}
+	rank, _ := view.parent.Rank1(view.begin + i)
+	return rank - view.rank, nil
+}
+
+// Rank0 returns number of the bits equal to `0` up to positin `i`
+func (view *SliceView) Rank0(i uint64) (uint64, error) {
+	rank, err := view.Rank1(i)
+	if err != nil {
+		return rank, err
+	}
+	return i - rank, nil
+}
+
+// Rank returns number of the bits equal to `b` up to positin `i`
+func (view *SliceView) Rank(i uint64, b bool) (uint64, error) {
+	if b {
+		return view.Rank1(i)
+	}
+	return view.Rank0(i)
+}
+
+// Select1 returns the position of the x-th occurence of 1
+func (view *SliceView) Select1(x uint64) (uint64, error) {
+	if x >= view.numOf1s {
+		return NotFound, ErrorOutOfRange
+	}
+	pos, _ := view.parent.Select1(view.rank + x)
+	return pos - view.begin, nil
+}
+
+// Select0 returns the position of the x-th occurence of 0
+func (view *SliceView) Select0(x uint64) (uint64, error) {
+	if x >= view.NumOfBits(false) {
+		return NotFound, ErrorOutOfRange
+	}
+	pos, _ := view.parent.Select0(view.begin - view.rank + x)
+	return pos - view.begin, nil
+}
+
+// Select returns the position of the x-th occurence of `b`
+func (view *SliceView) Select(x uint64, b bool) (uint64, error) {
+	if b {
+		return view.Select1(x)
+	}
+	return view.Select0(x)
+}
+
+// NextOne returns the position of the first 1 at or after position `i`.
+// It returns NotFound if there is no such bit.
+func (view *SliceView) NextOne(i uint64) (uint64, error) {
+	return view.next(i, true)
+}
+
+// NextZero returns the position of the first 0 at or after position `i`.
+// It returns NotFound if there is no such bit.
+func (view *SliceView) NextZero(i uint64) (uint64, error) {
+	return view.next(i, false)
+}
+
+// PrevOne returns the position of the last 1 at or before position `i`.
+// It returns NotFound if there is no such bit.
+func (view *SliceView) PrevOne(i uint64) (uint64, error) {
+	return view.prev(i, true)
+}
+
+// PrevZero returns the position of the last 0 at or before position `i`.
+// It returns NotFound if there is no such bit.
+func (view *SliceView) PrevZero(i uint64) (uint64, error) {
+	return view.prev(i, false)
+}
+
+func (view *SliceView) next(i uint64, b bool) (uint64, error) {
+	if i > view.size {
+		return NotFound, ErrorOutOfRange
+	}
+	pos, _ := view.parent.next(view.begin+i, b)
+	if pos == NotFound || pos >= view.begin+view.size {
+		return NotFound, nil
+	}
+	return pos - view.begin, nil
+}
+
+func (view *SliceView) prev(i uint64, b bool) (uint64, error) {
+	if i >= view.size {
+		return NotFound, ErrorOutOfRange
+	}
+	pos, _ := view.parent.prev(view.begin+i, b)
+	if pos == NotFound || pos < view.begin {
+		return NotFound, nil
+	}
+	return pos - view.begin, nil
+}
+
+// Size returns size of bit vector
+func (view *SliceView) Size() uint64 {
+	return view.size
+}
+
+// NumOfBits returns number of bits that matches with argument in the bit vector.
+func (view *SliceView) NumOfBits(b bool) uint64 {
+	if b {
+		return view.numOf1s
+	}
+	return view.size - view.numOf1s
+}
+
+// Get returns value from bit vector by index.
+func (view *ComplementView) Get(i uint64) (bool, error) {
+	if i >= view.parent.size {
+		return false, ErrorOutOfRange
+	}
+	b, _ := view.parent.Get(i)
+	return !b, nil
+}
+
+// GetBits returns bits from bit vector.
+func (view *ComplementView) GetBits(pos uint64, length uint64) (uint64, error) {
+	x, err := view.parent.GetBits(pos, length)
+	if err != nil {
+		return x, err
+	}
+	return mask(^x, length), nil
+}
+
+// Rank1 returns number of the bits equal to `1` up to positin `i`
+func (view *ComplementView) Rank1(i uint64) (uint64, error) {
+	return view.parent.Rank0(i)
+}
+
+// Rank0 returns number of the bits equal to `0` up to positin `i`
+func (view *ComplementView) Rank0(i uint64) (uint64, error) {
+	return view.parent.Rank1(i)
+}
+
+// Rank returns number of the bits equal to `b` up to positin `i`
+func (view *ComplementView) Rank(i uint64, b bool) (uint64, error) {
+	return view.parent.Rank(i, !b)
+}
+
+// Select1 returns the position of the x-th occurence of 1
+func (view *ComplementView) Select1(x uint64) (uint64, error) {
+	return view.parent.Select0(x)
+}
+
+// Select0 returns the position of the x-th occurence of 0
+func (view *ComplementView) Select0(x uint64) (uint64, error) {
+	return view.parent.Select1(x)
+}
+
+// Select returns the position of the x-th occurence of `b`
+func (view *ComplementView) Select(x uint64, b bool) (uint64, error) {
+	return view.parent.Select(x, !b)
+}
+
+// NextOne returns the position of the first 1 at or after position `i`.
+// It returns NotFound if there is no such bit.
+func (view *ComplementView) NextOne(i uint64) (uint64, error) {
+	return view.parent.NextZero(i)
+}
+
+// NextZero returns the position of the first 0 at or after position `i`.
+// It returns NotFound if there is no such bit.
+func (view *ComplementView) NextZero(i uint64) (uint64, error) {
+	return view.parent.NextOne(i)
+}
+
+// PrevOne returns the position of the last 1 at or before position `i`.
+// It returns NotFound if there is no such bit.
+func (view *ComplementView) PrevOne(i uint64) (uint64, error) {
+	return view.parent.PrevZero(i)
+}
+
+// PrevZero returns the position of the last 0 at or before position `i`.
+// It returns NotFound if there is no such bit.
+func (view *ComplementView) PrevZero(i uint64) (uint64, error) {
+	return view.parent.PrevOne(i)
+}
+
+// Size returns size of bit vector
+func (view *ComplementView) Size() uint64 {
+	return view.parent.size
+}
+
+// NumOfBits returns number of bits that matches with argument in the bit vector.
+func (view *ComplementView) NumOfBits(b bool) uint64 {
+	return view.parent.NumOfBits(!b)
+}
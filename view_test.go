@@ -0,0 +1,45 @@
+package sbvector
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSliceView(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var bits = make([]bool, 3000)
+	for i := range bits {
+		bits[i] = r.Intn(3) == 0
+	}
+	vec, _ := NewVectorFromBools(bits)
+	bv := vec.(*BitVectorData)
+	for _, lr := range [][2]int{{0, 0}, {0, 3000}, {1, 2}, {70, 1300}, {512, 1024}, {2999, 3000}} {
+		view, err := bv.Slice(uint64(lr[0]), uint64(lr[1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, _ := NewVectorFromBools(bits[lr[0]:lr[1]])
+		compareVectors(t, expected, view)
+	}
+	if _, err := bv.Slice(10, 9); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+	if _, err := bv.Slice(0, 3001); err != ErrorOutOfRange {
+		t.Error("Expected", ErrorOutOfRange, "got", err)
+	}
+}
+
+func TestComplementView(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, size := range []int{0, 64, 1000} {
+		var bits = make([]bool, size)
+		var inverted = make([]bool, size)
+		for i := range bits {
+			bits[i] = r.Intn(3) == 0
+			inverted[i] = !bits[i]
+		}
+		vec, _ := NewVectorFromBools(bits)
+		expected, _ := NewVectorFromBools(inverted)
+		compareVectors(t, expected, vec.(*BitVectorData).Complement())
+	}
+}
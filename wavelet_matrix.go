@@ -0,0 +1,332 @@
+package sbvector
+
+import (
+	"bytes"
+	"container/heap"
+	"encoding/binary"
+)
+
+// WaveletMatrix holds sequence of integers, and supports rank/select/access over the sequence.
+// Each level of the matrix is BitVectorData that holds one bit of each value, from the most significant bit.
+type WaveletMatrix struct {
+	levels  []*BitVectorData
+	zeros   []uint64
+	length  uint64
+	bitSize uint64
+}
+
+// ValueFrequency holds a value and number of its occurrences.
+type ValueFrequency struct {
+	Value     uint64
+	Frequency uint64
+}
+
+// NewWaveletMatrix returns new wavelet matrix that holds `values`.
+func NewWaveletMatrix(values []uint64) (*WaveletMatrix, error) {
+	var maxValue uint64
+	for _, v := range values {
+		if v > maxValue {
+			maxValue = v
+		}
+	}
+	wm := new(WaveletMatrix)
+	wm.length = uint64(len(values))
+	wm.bitSize = bitWidth(maxValue)
+	if wm.bitSize == 0 {
+		wm.bitSize = 1
+	}
+
+	current := make([]uint64, len(values))
+	copy(current, values)
+	next := make([]uint64, len(values))
+	for level := uint64(0); level < wm.bitSize; level++ {
+		var shift = wm.bitSize - level - 1
+		builder := NewVectorBuilder()
+		var numOf0s int
+		for _, v := range current {
+			if (v>>shift)&1 == 0 {
+				numOf0s++
+			}
+		}
+		var i0 = 0
+		var i1 = numOf0s
+		for _, v := range current {
+			if (v>>shift)&1 == 0 {
+				builder.PushBack(false)
+				next[i0] = v
+				i0++
+			} else {
+				builder.PushBack(true)
+				next[i1] = v
+				i1++
+			}
+		}
+		vec, err := builder.Build(true, true)
+		if err != nil {
+			return nil, err
+		}
+		wm.levels = append(wm.levels, vec.(*BitVectorData))
+		wm.zeros = append(wm.zeros, uint64(numOf0s))
+		current, next = next, current
+	}
+	return wm, nil
+}
+
+// Len returns number of values in the wavelet matrix.
+func (wm *WaveletMatrix) Len() uint64 {
+	return wm.length
+}
+
+// Access returns the i-th value.
+func (wm *WaveletMatrix) Access(i uint64) (uint64, error) {
+	if i >= wm.length {
+		return NotFound, ErrorOutOfRange
+	}
+	var value uint64
+	for level, vec := range wm.levels {
+		b, _ := vec.Get(i)
+		value <<= 1
+		if b {
+			value |= 1
+			i = wm.next1(level, i)
+		} else {
+			i = wm.next0(level, i)
+		}
+	}
+	return value, nil
+}
+
+// Rank returns number of occurrences of `c` up to position `i`.
+func (wm *WaveletMatrix) Rank(c uint64, i uint64) (uint64, error) {
+	if i > wm.length {
+		return NotFound, ErrorOutOfRange
+	}
+	if bitWidth(c) > wm.bitSize {
+		return 0, nil
+	}
+	begin, end := wm.narrow(c, 0, i)
+	return end - begin, nil
+}
+
+// Select returns the position of the k-th occurrence of `c`.
+func (wm *WaveletMatrix) Select(c uint64, k uint64) (uint64, error) {
+	if bitWidth(c) > wm.bitSize {
+		return NotFound, ErrorOutOfRange
+	}
+	begin, end := wm.narrow(c, 0, wm.length)
+	if begin+k >= end {
+		return NotFound, ErrorOutOfRange
+	}
+	var pos = begin + k
+	for level := len(wm.levels) - 1; level >= 0; level-- {
+		if (c>>(wm.bitSize-uint64(level)-1))&1 == 1 {
+			pos, _ = wm.levels[level].Select1(pos - wm.zeros[level])
+		} else {
+			pos, _ = wm.levels[level].Select0(pos)
+		}
+	}
+	return pos, nil
+}
+
+// Quantile returns the k-th smallest value in range [begin, end).
+func (wm *WaveletMatrix) Quantile(begin uint64, end uint64, k uint64) (uint64, error) {
+	if begin > end || end > wm.length || k >= end-begin {
+		return NotFound, ErrorOutOfRange
+	}
+	var value uint64
+	for level := range wm.levels {
+		var count0s = wm.next0(level, end) - wm.next0(level, begin)
+		value <<= 1
+		if k < count0s {
+			begin = wm.next0(level, begin)
+			end = wm.next0(level, end)
+		} else {
+			k -= count0s
+			value |= 1
+			begin = wm.next1(level, begin)
+			end = wm.next1(level, end)
+		}
+	}
+	return value, nil
+}
+
+// RangeFreq returns number of values `v` such that lower <= v < upper in range [begin, end).
+func (wm *WaveletMatrix) RangeFreq(begin uint64, end uint64, lower uint64, upper uint64) (uint64, error) {
+	if begin > end || end > wm.length {
+		return NotFound, ErrorOutOfRange
+	}
+	if lower >= upper {
+		return 0, nil
+	}
+	return wm.countLess(begin, end, upper) - wm.countLess(begin, end, lower), nil
+}
+
+// TopK returns at most `k` most frequent values in range [begin, end), in descending order of frequency.
+// Values of same frequency are ordered by ascending order.
+func (wm *WaveletMatrix) TopK(begin uint64, end uint64, k uint64) ([]ValueFrequency, error) {
+	if begin > end || end > wm.length {
+		return nil, ErrorOutOfRange
+	}
+	var result []ValueFrequency
+	queue := &waveletRangeQueue{}
+	if begin < end {
+		heap.Push(queue, waveletRange{begin, end, 0, 0, wm.bitSize})
+	}
+	for queue.Len() > 0 && uint64(len(result)) < k {
+		r := heap.Pop(queue).(waveletRange)
+		if r.level == len(wm.levels) {
+			result = append(result, ValueFrequency{r.value, r.end - r.begin})
+			continue
+		}
+		begin0, end0 := wm.next0(r.level, r.begin), wm.next0(r.level, r.end)
+		if begin0 < end0 {
+			heap.Push(queue, waveletRange{begin0, end0, r.level + 1, r.value << 1, r.shift - 1})
+		}
+		begin1, end1 := wm.next1(r.level, r.begin), wm.next1(r.level, r.end)
+		if begin1 < end1 {
+			heap.Push(queue, waveletRange{begin1, end1, r.level + 1, (r.value << 1) | 1, r.shift - 1})
+		}
+	}
+	return result, nil
+}
+
+// next0 returns the position in the next level of the `0` at position `i` of the level.
+func (wm *WaveletMatrix) next0(level int, i uint64) uint64 {
+	rank, _ := wm.levels[level].Rank0(i)
+	return rank
+}
+
+// next1 returns the position in the next level of the `1` at position `i` of the level.
+func (wm *WaveletMatrix) next1(level int, i uint64) uint64 {
+	rank, _ := wm.levels[level].Rank1(i)
+	return wm.zeros[level] + rank
+}
+
+// narrow returns the range in the last level that corresponds to occurrences of `c` in range [begin, end).
+func (wm *WaveletMatrix) narrow(c uint64, begin uint64, end uint64) (uint64, uint64) {
+	for level := range wm.levels {
+		if (c>>(wm.bitSize-uint64(level)-1))&1 == 1 {
+			begin = wm.next1(level, begin)
+			end = wm.next1(level, end)
+		} else {
+			begin = wm.next0(level, begin)
+			end = wm.next0(level, end)
+		}
+	}
+	return begin, end
+}
+
+// countLess returns number of values less than `x` in range [begin, end).
+func (wm *WaveletMatrix) countLess(begin uint64, end uint64, x uint64) uint64 {
+	if bitWidth(x) > wm.bitSize {
+		return end - begin
+	}
+	var count uint64
+	for level := range wm.levels {
+		if (x>>(wm.bitSize-uint64(level)-1))&1 == 1 {
+			count += wm.next0(level, end) - wm.next0(level, begin)
+			begin = wm.next1(level, begin)
+			end = wm.next1(level, end)
+		} else {
+			begin = wm.next0(level, begin)
+			end = wm.next0(level, end)
+		}
+	}
+	return count
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (wm *WaveletMatrix) MarshalBinary() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	binary.Write(buffer, binary.LittleEndian, &wm.length)
+	binary.Write(buffer, binary.LittleEndian, &wm.bitSize)
+	for _, vec := range wm.levels {
+		buf, err := vec.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(buf)
+	}
+	return marshalContainer(kindWavelet, 0, buffer.Bytes()), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (wm *WaveletMatrix) UnmarshalBinary(data []byte) error {
+	_, data, err := unmarshalContainer(data, kindWavelet, true)
+	if err != nil {
+		return err
+	}
+	if uint64(len(data)) < sizeOfInt64*2 {
+		return ErrorInvalidLength
+	}
+	wm.length = binary.LittleEndian.Uint64(data)
+	wm.bitSize = binary.LittleEndian.Uint64(data[sizeOfInt64:])
+	if wm.bitSize == 0 || wm.bitSize > sBlockSize {
+		return ErrorInvalidFormat
+	}
+	wm.levels = make([]*BitVectorData, wm.bitSize)
+	wm.zeros = make([]uint64, wm.bitSize)
+	var buf = data[sizeOfInt64*2:]
+	for level := range wm.levels {
+		vec := new(BitVectorData)
+		var err error
+		buf, err = unmarshalEmbedded(vec, buf)
+		if err != nil {
+			return err
+		}
+		if vec.Size() != wm.length {
+			return ErrorInvalidFormat
+		}
+		wm.levels[level] = vec
+		wm.zeros[level] = vec.NumOfBits(false)
+	}
+	if len(buf) != 0 {
+		return ErrorInvalidFormat
+	}
+	return nil
+}
+
+// waveletRange is a range in a level of wavelet matrix that holds values of the same prefix.
+type waveletRange struct {
+	begin uint64
+	end   uint64
+	level int
+	value uint64
+	shift uint64
+}
+
+// minValue returns the smallest value that the range can hold.
+func (r waveletRange) minValue() uint64 {
+	return r.value << r.shift
+}
+
+// waveletRangeQueue is priority queue of waveletRange ordered by width of the range.
+type waveletRangeQueue []waveletRange
+
+func (q waveletRangeQueue) Len() int {
+	return len(q)
+}
+
+func (q waveletRangeQueue) Less(i, j int) bool {
+	var wi = q[i].end - q[i].begin
+	var wj = q[j].end - q[j].begin
+	if wi != wj {
+		return wi > wj
+	}
+	return q[i].minValue() < q[j].minValue()
+}
+
+func (q waveletRangeQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *waveletRangeQueue) Push(x interface{}) {
+	*q = append(*q, x.(waveletRange))
+}
+
+func (q *waveletRangeQueue) Pop() interface{} {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,125 @@
+package sbvector
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestWaveletMatrix(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	values := make([]uint64, 1000)
+	for i := range values {
+		values[i] = uint64(r.Intn(37))
+	}
+	wm, err := NewWaveletMatrix(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := wm.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wm2 := new(WaveletMatrix)
+	if err := wm2.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, m := range []*WaveletMatrix{wm, wm2} {
+		if m.Len() != uint64(len(values)) {
+			t.Error("Expected", len(values), "got", m.Len())
+		}
+		counts := make(map[uint64]uint64)
+		for i, v := range values {
+			x, err := m.Access(uint64(i))
+			if err != nil || x != v {
+				t.Fatal("Access", i, "Expected", v, "got", x)
+			}
+			rank, err := m.Rank(v, uint64(i))
+			if err != nil || rank != counts[v] {
+				t.Fatal("Rank", v, i, "Expected", counts[v], "got", rank)
+			}
+			pos, err := m.Select(v, counts[v])
+			if err != nil || pos != uint64(i) {
+				t.Fatal("Select", v, counts[v], "Expected", i, "got", pos)
+			}
+			counts[v]++
+		}
+
+		for _, q := range [][2]uint64{{0, 1000}, {10, 20}, {500, 777}, {999, 1000}} {
+			begin, end := q[0], q[1]
+			sorted := make([]uint64, end-begin)
+			copy(sorted, values[begin:end])
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+			for k := range sorted {
+				x, err := m.Quantile(begin, end, uint64(k))
+				if err != nil || x != sorted[k] {
+					t.Fatal("Quantile", begin, end, k, "Expected", sorted[k], "got", x)
+				}
+			}
+
+			freq := make(map[uint64]uint64)
+			for _, v := range values[begin:end] {
+				freq[v]++
+			}
+			for lower := uint64(0); lower < 40; lower += 7 {
+				for upper := lower; upper < 45; upper += 5 {
+					var expected uint64
+					for v, f := range freq {
+						if lower <= v && v < upper {
+							expected += f
+						}
+					}
+					count, err := m.RangeFreq(begin, end, lower, upper)
+					if err != nil || count != expected {
+						t.Fatal("RangeFreq", begin, end, lower, upper, "Expected", expected, "got", count)
+					}
+				}
+			}
+
+			var expected []ValueFrequency
+			for v, f := range freq {
+				expected = append(expected, ValueFrequency{v, f})
+			}
+			sort.Slice(expected, func(i, j int) bool {
+				if expected[i].Frequency != expected[j].Frequency {
+					return expected[i].Frequency > expected[j].Frequency
+				}
+				return expected[i].Value < expected[j].Value
+			})
+			if len(expected) > 5 {
+				expected = expected[:5]
+			}
+			top, err := m.TopK(begin, end, 5)
+			if err != nil || len(top) != len(expected) {
+				t.Fatal("TopK", begin, end, "Expected", expected, "got", top)
+			}
+			for i := range top {
+				if top[i] != expected[i] {
+					t.Fatal("TopK", begin, end, "Expected", expected, "got", top)
+				}
+			}
+		}
+
+		if rank, err := m.Rank(100, 1000); err != nil || rank != 0 {
+			t.Error("Expected", 0, "got", rank)
+		}
+		if _, err := m.Access(1000); err != ErrorOutOfRange {
+			t.Error()
+		}
+		if _, err := m.Select(values[0], counts[values[0]]); err != ErrorOutOfRange {
+			t.Error()
+		}
+		if _, err := m.Quantile(10, 10, 0); err != ErrorOutOfRange {
+			t.Error()
+		}
+	}
+
+	if err := wm2.UnmarshalBinary(buf[:len(buf)-1]); err == nil {
+		t.Error("Expected error")
+	}
+	buf[len(buf)/2] ^= 0x01
+	if err := wm2.UnmarshalBinary(buf); err != ErrorChecksumMismatch {
+		t.Error("Expected", ErrorChecksumMismatch, "got", err)
+	}
+}